@@ -3,30 +3,63 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"github.com/ghodss/yaml"
 	scv1beta1 "github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1beta1"
 	servicecatalogclienset "github.com/kubernetes-incubator/service-catalog/pkg/client/clientset_generated/clientset/typed/servicecatalog/v1beta1"
 	log "github.com/sirupsen/logrus"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/client"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/output"
 	"github.com/snowdrop/odo-scaffold-plugin/pkg/scaffold"
 	"github.com/snowdrop/odo-scaffold-plugin/pkg/ui"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/validation"
 	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	"io"
 	"io/ioutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
 	"text/template"
+	"time"
 )
 
+// publicServiceEndpoint is the URL of the public generator service, which is provided on a best-effort basis
+const publicServiceEndpoint = "https://generator.snowdrop.me"
+
+// ServiceEndpoint is the default generator service URL, which can be overridden at build time using
+// -ldflags "-X main.ServiceEndpoint=<url>" or at run time using the SCAFFOLD_SERVICE_URL environment variable
+var ServiceEndpoint = publicServiceEndpoint
+
+// version information, set at build time using -ldflags "-X main.version=<version> -X main.commit=<commit> -X main.date=<date>"
+var (
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+// serviceURLEnvVar is the environment variable overriding the compiled-in generator service URL
+const serviceURLEnvVar = "SCAFFOLD_SERVICE_URL"
+
+// tokenEnvVar is the environment variable providing the bearer token used to authenticate with the generator service. It is not
+// available as a flag so that it doesn't end up in the shell history or the process list.
+const tokenEnvVar = "SCAFFOLD_TOKEN"
+
 const (
-	ServiceEndpoint          = "https://generator.snowdrop.me"
 	ReleaseSuffix            = ".RELEASE"
 	serviceCatalogAnnotation = `@ServiceCatalog(instances = @ServiceCatalogInstance(
         name = "{{.Name}}",
@@ -41,76 +74,229 @@ const (
 `
 )
 
+// buildTools lists the supported build systems, sorted so that they can be looked up using isContained
+var buildTools = []string{"gradle", "maven"}
+
+// packagings lists the supported packaging types, sorted so that they can be looked up using isContained
+var packagings = []string{"jar", "war"}
+
 func main() {
+	ctx, cancel := interruptibleContext()
+	defer cancel()
+
 	p := &scaffold.Project{}
+	var dryRun, batch, force, noProgress, quiet, verbose, gitInit, archive, keepArchive, keepPartial, open, verify bool
+	var configFile, outputFormat, gitRemote, logFormat, editor, category, specFile, exportSpecFile, workDir string
+	var moduleList, parameters, postHooks []string
+	// maxArchiveSize protects against misbehaving generator services sending gigantic responses
+	maxArchiveSize := byteSize(100 << 20)
+	limits := defaultExtractionLimits
+	// spec is the project spec read from the file specified using --from-spec, if any
+	var spec *scaffold.Project
+	// generator is the client shared by all requests made to the generator service, so that connections are reused. It is only
+	// configured once flags have been parsed.
+	generator := &client.Client{}
+
+	// create creates the project, recording the outcome in the specified result. Informational output, including the output of
+	// post-creation hooks, is written to out, and the archive is only written to archiveOut if not nil.
+	create := func(cmd *cobra.Command, result *scaffoldResult, out, archiveOut io.Writer) error {
+		p.Modules = mergeModules(p.Modules, moduleList)
+		var err error
+		if p.Parameters, err = parseParameters(parameters); err != nil {
+			return err
+		}
+		if spec != nil {
+			// parameters set using flags override the ones of the spec
+			for k, v := range spec.Parameters {
+				if _, ok := p.Parameters[k]; !ok {
+					p.Parameters[k] = v
+				}
+			}
+		}
 
-	createCmd := &cobra.Command{
-		Use:   "scaffold [flags]",
-		Short: "Create a Spring Boot maven project",
-		Long:  `Create a Spring Boot maven project.`,
-		Args:  cobra.RangeArgs(0, 1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// fail fast if needed
-			useTemplate := len(p.Template) > 0
-			useModules := len(p.Modules) > 0
-			if useTemplate && useModules {
-				return fmt.Errorf("specifying both modules and template is not currently supported")
+		// fail fast if needed
+		useTemplate := len(p.Template) > 0
+		useModules := len(p.Modules) > 0
+		if useTemplate && useModules {
+			return invalidf("--template and --module are mutually exclusive: a project is either created from a template or from modules")
+		}
+		if len(gitRemote) > 0 && !gitInit {
+			return invalidf("--git-remote requires --git-init")
+		}
+		if gitInit && archive {
+			return invalidf("--git-init cannot be used with --archive since the project is not extracted")
+		}
+		if open && archive {
+			return invalidf("--open cannot be used with --archive since the project is not extracted")
+		}
+		if len(editor) > 0 && !open {
+			return invalidf("--editor requires --open")
+		}
+		if keepArchive && (archive || verify) {
+			return invalidf("--keep-archive cannot be used with --archive or --verify since the project is not extracted")
+		}
+		if keepPartial && (archive || verify) {
+			return invalidf("--keep-partial cannot be used with --archive or --verify since the project is not extracted")
+		}
+		if len(postHooks) > 0 && (archive || verify) {
+			return invalidf("--post-hook cannot be used with --archive or --verify since the project is not extracted")
+		}
+		if verify && (archive || gitInit || open) {
+			return invalidf("--verify cannot be used with --archive, --git-init or --open since no project is created")
+		}
+		currentDir, err := baseDir(workDir)
+		if err != nil {
+			return err
+		}
+		if !isContained(p.BuildTool, buildTools) {
+			return invalidf("unknown build system '%s', supported ones are: %s", p.BuildTool, strings.Join(buildTools, ", "))
+		}
+		if !isContained(p.Packaging, packagings) {
+			return invalidf("unknown packaging '%s', supported ones are: %s", p.Packaging, strings.Join(packagings, ", "))
+		}
+		if batch {
+			if missing := missingRequiredFields(p); len(missing) > 0 && spec != nil {
+				return invalidf("missing required values in project spec %s: %s", specFile, strings.Join(missing, ", "))
+			} else if len(missing) > 0 {
+				return invalidf("missing required values in batch mode: %s", strings.Join(missing, ", "))
 			}
+		}
 
-			c := getGeneratorServiceConfig(p.UrlService)
+		// retrieve the modules in the background while the configuration is retrieved and the user answers prompts, starting
+		// with the ones compatible with the specified Spring Boot version if any, or the default one otherwise
+		fetcher := newModuleFetcher(ctx, generator)
+		hasSB := len(p.SpringBootVersion) > 0
+		if hasSB && !p.Offline {
+			fetcher.prefetch(withReleaseSuffix(p.SpringBootVersion))
+		}
 
-			// first select Spring Boot version
-			versions, defaultVersion := c.GetBOMMap()
-			hasSB := len(p.SpringBootVersion) > 0
+		// retrieving the configuration first also makes sure that the generator service is reachable before prompting the user
+		c, err := getGeneratorServiceConfig(ctx, p, generator)
+		if err != nil {
+			return err
+		}
 
-			// modify given SB version if needed since we allow 2.1.3 instead of full 2.1.3.RELEASE
-			if hasSB && !strings.HasSuffix(p.SpringBootVersion, ReleaseSuffix) {
-				p.SpringBootVersion = p.SpringBootVersion + ReleaseSuffix
+		// first select Spring Boot version
+		versions, defaultVersion, err := getBOMs(c)
+		if err != nil {
+			return err
+		}
+		if !hasSB && !p.Offline && len(defaultVersion) > 0 {
+			fetcher.prefetch(defaultVersion)
+		}
+
+		// resolve the given SB version since we allow 2.1.3 instead of full 2.1.3.RELEASE, or 2.1 if it identifies a single version
+		if hasSB {
+			if p.SpringBootVersion, err = resolveSpringBootVersion(p.SpringBootVersion, versions); err != nil {
+				return err
 			}
+			if !p.Offline {
+				fetcher.prefetch(p.SpringBootVersion)
+			}
+		}
+
+		// the interactive selection is split in steps so that the user can go back to a previous selection, each step starting
+		// from the values that were provided by the user
+		providedSB, providedBOM, providedTemplate, providedModules := p.SpringBootVersion, p.SnowdropBomVersion, p.Template, p.Modules
+		providedUseTemplate, providedUseModules := useTemplate, useModules
+		var bom scaffold.Bom
+		templateNames := c.GetTemplateNames()
+
+		selectSpringBootVersion := func(canGoBack bool) (bool, error) {
+			p.SpringBootVersion = providedSB
 
 			// if the user didn't specify an SB version, ask for it
 			if !hasSB {
-				p.SpringBootVersion = ui.Select("Spring Boot version", scaffold.GetSpringBootVersions(versions), defaultVersion)
+				p.SpringBootVersion, err = ui.SelectE("Spring Boot version", scaffold.GetSpringBootVersions(versions), defaultVersion)
+				bom = versions[p.SpringBootVersion]
+				return true, err
 			}
 
 			// check that the given SB version yields a known BOM, if not ask the user for a supported SB version
-			bom, ok := versions[p.SpringBootVersion]
-			if !ok {
-				s := ui.ErrorMessage("Unknown Spring Boot version", p.SpringBootVersion)
-				p.SpringBootVersion = ui.Select(s, scaffold.GetSpringBootVersions(versions), defaultVersion)
-			} else if hasSB {
+			var ok bool
+			bom, ok = versions[p.SpringBootVersion]
+			if ok {
 				// if we provided an SB version and it yields a valid BOM, display it
-				ui.OutputSelection("Selected Spring Boot", p.SpringBootVersion)
+				ui.OutputSelection(out, "Selected Spring Boot", p.SpringBootVersion)
+				return false, nil
+			}
+			if batch {
+				return false, invalidf("unknown Spring Boot version: %s", p.SpringBootVersion)
+			}
+			s := ui.ErrorMessage("Unknown Spring Boot version", p.SpringBootVersion)
+			p.SpringBootVersion, err = ui.SelectE(s, scaffold.GetSpringBootVersions(versions), defaultVersion)
+			bom = versions[p.SpringBootVersion]
+			return true, err
+		}
+
+		selectBOM := func(canGoBack bool) (bool, error) {
+			p.SnowdropBomVersion = providedBOM
+			if len(p.SnowdropBomVersion) > 0 {
+				// an explicitly provided BOM version must match the selected Spring Boot version
+				if err := validateBOMVersion(c, p.SpringBootVersion, p.SnowdropBomVersion); err != nil {
+					return false, err
+				}
+				if p.UseSupported && p.SnowdropBomVersion != bom.Supported {
+					log.Warnf("Using explicitly provided Snowdrop BOM %s instead of the supported one", p.SnowdropBomVersion)
+				}
+				p.UseSupported = p.SnowdropBomVersion == bom.Supported
+				ui.OutputSelection(out, "Selected Snowdrop BOM", p.SnowdropBomVersion)
+				return false, nil
 			}
 
 			p.SnowdropBomVersion = bom.Snowdrop
 			if len(bom.Supported) > 0 {
-				if !cmd.Flag("supported").Changed {
-					p.UseSupported = ui.Proceed(fmt.Sprintf("Use %s supported version", p.SpringBootVersion))
+				if !supportedFlagChanged(cmd) && !batch {
+					p.UseSupported, err = ui.ProceedE(fmt.Sprintf("Use %s supported version", p.SpringBootVersion))
+					if err != nil {
+						return false, err
+					}
 				}
 
 				if p.UseSupported {
 					p.SnowdropBomVersion = c.GetSupportedVersionFor(p.SpringBootVersion)
-					ui.OutputSelection("Selected supported Spring Boot", p.SnowdropBomVersion)
+					ui.OutputSelection(out, "Selected supported Spring Boot", p.SnowdropBomVersion)
 				}
+			} else if p.UseSupported {
+				log.Warnf("No supported Snowdrop BOM is available for Spring Boot %s, using %s", p.SpringBootVersion, p.SnowdropBomVersion)
+			}
+			return false, nil
+		}
+
+		selectTemplateOrModules := func(canGoBack bool) (bool, error) {
+			p.Template, p.Modules = providedTemplate, providedModules
+			useTemplate, useModules = providedUseTemplate, providedUseModules
+			multiSelect := ui.MultiSelectSearchableE
+			if canGoBack {
+				multiSelect = ui.MultiSelectSearchableWithBackE
 			}
 
 			// deal with template
-			templateNames := c.GetTemplateNames()
 			if useTemplate {
-				if !isContained(p.Template, templateNames) {
-					// provided template doesn't exist, select one from available
-					p.Template = ui.Select(ui.ErrorMessage("Unknown template", p.Template), templateNames)
-				} else {
-					ui.OutputSelection("Selected template", p.Template)
+				if len(p.Template) > 1 && !c.ComposableTemplates {
+					return false, invalidf("the generator service doesn't support combining templates, select a single one instead of %s", p.Template)
 				}
+				unknown := unknownElements(p.Template, templateNames)
+				if len(unknown) == 0 {
+					ui.OutputSelection(out, "Selected template", p.Template.String())
+					return false, nil
+				}
+				if batch {
+					return false, invalidf("unknown template: %s", strings.Join(unknown, ", "))
+				}
+				// provided template doesn't exist, select from available ones
+				p.Template, err = selectTemplates(c, ui.ErrorMessage("Unknown template", strings.Join(unknown, ", ")), templateNames, canGoBack)
+				return true, err
 			}
 
 			// deal with modules
 			if useModules {
 				// check if all provided modules are known
-				moduleNames := getCompatibleModuleNamesFor(p)
-				sort.Strings(moduleNames)
+				modules, err := fetcher.modules(p.SpringBootVersion)
+				if err != nil {
+					return false, err
+				}
+				moduleNames := scaffold.GetModuleNamesFor(modules)
 				unknown := make([]string, 0, len(moduleNames))
 				valid := make([]string, 0, len(moduleNames))
 				for _, module := range p.Modules {
@@ -124,119 +310,1342 @@ func main() {
 				if !isContained("core", valid) {
 					valid = append(valid, "core")
 				}
-				ui.OutputSelection("Selected modules", strings.Join(valid, ","))
+				ui.OutputSelection(out, "Selected modules", strings.Join(valid, ","))
 
-				if len(unknown) > 0 {
-					p.Modules = ui.MultiSelect(ui.ErrorMessage("Unknown modules", strings.Join(unknown, ",")), moduleNames, valid)
+				if len(unknown) == 0 {
+					return false, nil
+				}
+				if batch {
+					return false, invalidf("unknown modules: %s", strings.Join(unknown, ","))
 				}
+				p.Modules, err = multiSelect(ui.ErrorMessage("Unknown modules", strings.Join(unknown, ",")), scaffold.GetModuleDescriptionsFor(modules), scaffold.GetModuleCategoriesFor(modules), valid)
+				return true, err
 			}
 
 			// if user didn't specify either template or modules, ask what to do
-			if !useModules && !useTemplate {
-				if ui.Proceed("Create from template") {
-					p.Template = ui.Select("Available templates", templateNames)
-					useTemplate = true
-				} else {
-					p.Modules = ui.MultiSelect("Select modules", getCompatibleModuleNamesFor(p), []string{"core"})
-					useModules = true
-				}
+			fromTemplate, err := ui.ProceedE("Create from template")
+			if err != nil {
+				return false, err
+			}
+			if fromTemplate {
+				p.Template, err = selectTemplates(c, "Available templates", templateNames, canGoBack)
+				useTemplate = err == nil
+				return true, err
 			}
 
-			// if we're using a template, ask additional information
-			if useTemplate {
-				// only ask about ap4k if the user didn't specify the flag
-				if !cmd.Flag("ap4k").Changed {
-					p.UseAp4k = ui.Proceed("Use ap4k to generate OpenShift / Kubernetes resources")
+			modules, err := fetcher.modules(p.SpringBootVersion)
+			if err != nil {
+				return false, err
+			}
+			if modules, err = filterModulesByCategory(modules, category); err != nil {
+				return false, err
+			}
+			p.Modules, err = multiSelect("Select modules", scaffold.GetModuleDescriptionsFor(modules), scaffold.GetModuleCategoriesFor(modules), []string{"core"})
+			useModules = err == nil
+			return true, err
+		}
+
+		if err := ui.RunSteps(selectSpringBootVersion, selectBOM, selectTemplateOrModules); err != nil {
+			return err
+		}
+
+		// if we're using a template, ask additional information
+		if useTemplate {
+			// only ask about ap4k if the user didn't specify the flag
+			if !cmd.Flag("ap4k").Changed && !batch {
+				p.UseAp4k, err = ui.ProceedE("Use ap4k to generate OpenShift / Kubernetes resources")
+				if err != nil {
+					return err
 				}
+			}
 
-				if p.UseAp4k && ui.Proceed("Create a service from service catalog") {
+			if p.UseAp4k && !batch {
+				createService, err := ui.ProceedE("Create a service from service catalog")
+				if err != nil {
+					return err
+				}
+				if createService {
 					generateAp4kAnnotations()
 				}
 			}
+		}
+
+		// in batch mode, use the values that would otherwise be suggested to the user
+		if batch {
+			if err := validation.ValidateGroupId(p.GroupId); err != nil {
+				return invalidInput(err)
+			}
+			if err := validation.ValidateArtifactId(p.ArtifactId); err != nil {
+				return invalidInput(err)
+			}
+			if err := validation.ValidateVersion(p.Version); err != nil {
+				return invalidInput(err)
+			}
+			if len(p.PackageName) == 0 {
+				p.PackageName = defaultPackageName(p)
+			}
+			if err := validation.ValidatePackageName(p.PackageName); err != nil {
+				return invalidInput(err)
+			}
+			if len(p.OutDir) == 0 {
+				p.OutDir = p.ArtifactId
+			}
+			if err := validation.ValidateOutDir(p.OutDir); err != nil {
+				return invalidInput(err)
+			}
+		}
+
+		if p.GroupId, err = ui.AskValidatedToE(out, "Group Id", p.GroupId, validation.GroupIdValidator, "me.snowdrop"); err != nil {
+			return err
+		}
+		if p.ArtifactId, err = ui.AskValidatedToE(out, "Artifact Id", p.ArtifactId, validation.ArtifactIdValidator, "myproject"); err != nil {
+			return err
+		}
+		if p.Version, err = ui.AskValidatedToE(out, "Version", p.Version, validation.VersionValidator, "1.0.0-SNAPSHOT"); err != nil {
+			return err
+		}
+		// suggest a valid version of the provided package name if it is invalid
+		suggestedPackageName := validation.SanitizePackageName(p.PackageName)
+		if len(p.PackageName) == 0 {
+			suggestedPackageName = defaultPackageName(p)
+		}
+		if p.PackageName, err = ui.AskValidatedToE(out, "Package name", p.PackageName, validation.PackageNameValidator, suggestedPackageName); err != nil {
+			return err
+		}
+		if p.JavaVersion, err = selectJavaVersion(out, c, p.JavaVersion, batch); err != nil {
+			return err
+		}
+		// only ask about packaging if the user didn't specify the flag
+		if !cmd.Flag("packaging").Changed && !batch {
+			if p.Packaging, err = ui.SelectE("Packaging", packagings, p.Packaging); err != nil {
+				return err
+			}
+		}
+
+		locationMessage := fmt.Sprintf("Project location (child directory of %s or absolute path)", currentDir)
+		if p.OutDir, err = ui.AskValidatedToE(out, locationMessage, p.OutDir, validation.OutDirValidator, p.ArtifactId); err != nil {
+			return err
+		}
+		// the location is kept unexpanded so that it can be computed again if the settings are edited when reviewing them, the
+		// default one following the artifact id
+		outDir := p.OutDir
+		if outDir == p.ArtifactId {
+			outDir = "{artifactId}"
+		}
+		var dir string
+		// locate computes the project location from the current settings, making sure that the project can be created there
+		locate := func() (string, error) {
+			var err error
+			if p.OutDir, err = expandOutDir(outDir, p); err != nil {
+				return "", invalidInput(err)
+			}
+			if dir, err = projectDir(currentDir, p.OutDir); err != nil {
+				return "", invalidInput(err)
+			}
+			location := dir
+			result.Dir, result.Archive = "", ""
+			switch {
+			case verify:
+				// nothing is written to the project location when only verifying the generated archive
+			case archiveOut != nil:
+				location = stdoutLocation
+			case archive:
+				location = filepath.Join(currentDir, p.ArtifactId+".zip")
+				result.Archive = location
+				if _, err := os.Stat(location); err == nil && !force {
+					if batch {
+						return "", invalidf("%s already exists, remove it or use --force to overwrite it", location)
+					}
+					overwrite, err := ui.ProceedE(fmt.Sprintf("%s already exists, overwrite it?", location))
+					if err != nil {
+						return "", err
+					}
+					if !overwrite {
+						return "", invalidf("%s already exists, remove it or use --force to overwrite it", location)
+					}
+				}
+			default:
+				// interactively, let the user either overwrite the content of an existing directory or choose another location
+				for !force {
+					nonEmpty, err := isNonEmptyDir(dir)
+					if err != nil {
+						return "", err
+					}
+					if !nonEmpty {
+						break
+					}
+					if batch {
+						return "", invalidf("%s already exists and is not empty, choose another location or use --force to overwrite its content", dir)
+					}
+					overwrite, err := ui.ProceedE(fmt.Sprintf("Directory %s exists and is not empty, overwrite its content?", dir))
+					if err != nil {
+						return "", err
+					}
+					if overwrite {
+						break
+					}
+					if outDir, err = ui.AskValidatedToE(out, locationMessage, "", validation.OutDirValidator); err != nil {
+						return "", err
+					}
+					if p.OutDir, err = expandOutDir(outDir, p); err != nil {
+						return "", invalidInput(err)
+					}
+					if dir, err = projectDir(currentDir, p.OutDir); err != nil {
+						return "", err
+					}
+					location = dir
+				}
+				result.Dir = dir
+				if keepArchive {
+					result.Archive = dir + ".zip"
+				}
+			}
+			return location, nil
+		}
+		location, err := locate()
+		if err != nil {
+			return err
+		}
+
+		// make sure that the selected modules can actually be used with the selected Spring Boot version
+		if useModules {
+			moduleNames, err := fetcher.moduleNames(p.SpringBootVersion)
+			if err != nil {
+				return err
+			}
+			if incompatible := unknownElements(p.Modules, moduleNames); len(incompatible) > 0 {
+				return invalidf("modules not compatible with Spring Boot %s: %s", p.SpringBootVersion, strings.Join(incompatible, ", "))
+			}
+			if p.Modules, err = resolveConflicts(c, p.Modules, batch, ui.SelectE); err != nil {
+				return err
+			}
+		}
+
+		// let the user fix the collected settings before actually creating the project
+		if !batch && !verify && !dryRun {
+			confirmed, err := reviewProject(out, p, location, &outDir, locate, ui.SelectInOrderE, ui.AskValidatedE)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				fmt.Fprintln(out, ui.Warning(out, "Project creation aborted"))
+				return nil
+			}
+		}
+
+		// the reported URL doesn't disclose the values of sensitive parameters, which end up in logs
+		result.URL = generator.GenerateURL(p.Redacted())
+		log.WithField("url", result.URL).Info("Generation request")
+		if len(exportSpecFile) > 0 {
+			if err := scaffold.SaveProject(exportSpecFile, p); err != nil {
+				return fmt.Errorf("couldn't export project spec to %s: %w", exportSpecFile, err)
+			}
+			log.Infof("Project spec exported to %s", exportSpecFile)
+			if redacted := sensitiveParameters(p.Parameters); len(redacted) > 0 {
+				log.Warnf("Values of sensitive parameters %s are not exported, pass them again with --param when creating the project "+
+					"from %s", strings.Join(redacted, ", "), exportSpecFile)
+			}
+		}
+		if dryRun {
+			return nil
+		}
+
+		stopSpinner := func() {}
+		if !noProgress && !quiet {
+			stopSpinner = ui.StartSpinner(out, "Generating project...")
+		}
+		defer stopSpinner()
+
+		content, err := generator.Generate(ctx, p)
+		stopSpinner()
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		archiveSizeErr := fmt.Errorf("generated archive exceeds the maximum size of %s set by --max-archive-size", maxArchiveSize)
+		if content.Length > int64(maxArchiveSize) {
+			return archiveSizeErr
+		}
+
+		// the spinner is replaced by the download progress once the service starts sending the project
+		var reader io.Reader = &limitedReader{reader: content, remaining: int64(maxArchiveSize), err: archiveSizeErr}
+		stopProgress := func() {}
+		if !noProgress && !quiet {
+			reader, stopProgress = ui.StartProgress(out, "Downloading project", reader, content.Length)
+		}
+
+		// only a project directory created by the extraction can safely be removed if it is interrupted
+		_, statErr := os.Stat(dir)
+		created := os.IsNotExist(statErr)
+		switch {
+		case verify:
+			result.Entries, err = verifyArchive(reader)
+		case archiveOut != nil:
+			if _, err = io.Copy(archiveOut, reader); err != nil {
+				err = fmt.Errorf("failed to write archive to stdout: %w", err)
+			}
+		case archive:
+			err = saveArchive(reader, location)
+		default:
+			err = extractProject(ctx, reader, dir, keepArchive, limits)
+		}
+		stopProgress()
+		if ctx.Err() != nil {
+			if !verify && !archive {
+				cleanupPartialProject(dir, created, keepPartial)
+			}
+			return client.ErrCancelled
+		}
+		if err != nil {
+			return err
+		}
+
+		if verify {
+			fmt.Fprintln(out, ui.Success(out, fmt.Sprintf("Generated archive is valid and contains %d entries:", len(result.Entries))))
+			for _, name := range result.Entries {
+				fmt.Fprintln(out, name)
+			}
+			return nil
+		}
+
+		// the project has been created at this point so failing to initialize the repository shouldn't fail the command
+		if gitInit {
+			if err := initGitRepository(dir, gitRemote); err != nil {
+				log.Warnf("Couldn't initialize git repository in %s: %v", dir, err)
+			}
+		}
+
+		if !quiet && archive {
+			fmt.Fprintln(out, ui.Success(out, fmt.Sprintf("Project archive created at %s", location)))
+		} else if !quiet && keepArchive {
+			fmt.Fprintln(out, ui.Success(out, fmt.Sprintf("Project created at %s, archive kept at %s", dir, result.Archive)))
+		} else if !quiet {
+			fmt.Fprintln(out, ui.Success(out, fmt.Sprintf("Project created at %s", dir)))
+		}
+
+		result.Hooks, err = runPostHooks(ctx, dir, postHooks, out, os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		if open {
+			if len(editor) == 0 {
+				editor = os.Getenv("EDITOR")
+			}
+			if err := openDir(dir, editor); err != nil {
+				log.Warnf("Couldn't open %s: %v", dir, err)
+			}
+		}
+		return nil
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "scaffold [flags]",
+		Short: "Create a Spring Boot maven project",
+		Long:  `Create a Spring Boot maven project.`,
+		Args:  cobra.RangeArgs(0, 1),
+		// errors are reported once by main
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case quiet && verbose:
+				return invalidf("--quiet and --verbose cannot be used together")
+			case p.Offline && p.NoCache:
+				return invalidf("--offline and --no-cache cannot be used together")
+			case len(p.Password) > 0 && len(p.Username) == 0:
+				return invalidf("--password requires --username")
+			case quiet:
+				log.SetLevel(log.WarnLevel)
+			case verbose:
+				log.SetLevel(log.DebugLevel)
+			}
+			switch logFormat {
+			case "text":
+			case "json":
+				log.SetFormatter(&log.JSONFormatter{})
+			default:
+				return invalidf("unsupported log format '%s', supported ones are: text, json", logFormat)
+			}
+			p.Token = os.Getenv(tokenEnvVar)
+			if len(p.Token) > 0 && len(p.Username) > 0 {
+				log.Warnf("Both $%s and --username are set: the bearer token is used instead of --username / --password", tokenEnvVar)
+			}
+			if len(p.SaveRequest) > 0 {
+				// only keep the requests of the current run, the client appending each of them to the file
+				if err := ioutil.WriteFile(p.SaveRequest, nil, 0600); err != nil {
+					return fmt.Errorf("couldn't create request file %s: %w", p.SaveRequest, err)
+				}
+			}
+			if p.Insecure {
+				log.Warn("TLS certificate verification is disabled (--insecure): the connection to the generator service is not secure")
+			}
+			if err := applyDefaults(cmd, configFile); err != nil {
+				return err
+			}
+			if len(specFile) > 0 {
+				var err error
+				if spec, err = scaffold.LoadProject(specFile); err != nil {
+					return fmt.Errorf("couldn't read project spec %s: %w", specFile, err)
+				}
+				applySpec(cmd, p, spec)
+				// a spec fully describes the project
+				batch = true
+			}
+			// avoid empty path segments when computing endpoint URLs, which some servers reject
+			p.UrlService = normalizeServiceURL(p.UrlService)
+			if !quiet && !p.Offline && isPublicServiceEndpoint(p.UrlService) {
+				log.Warnf("Using the public generator service %s, which may be rate-limited or unavailable: consider using your own "+
+					"deployment with --urlservice or $%s for production use", p.UrlService, serviceURLEnvVar)
+			}
+
+			configured, err := client.New(p)
+			if err != nil {
+				return err
+			}
+			*generator = *configured
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			renderer, err := newRenderer(outputFormat)
+			if err != nil {
+				return err
+			}
+			if p.OutDir == stdoutLocation {
+				if !archive {
+					return invalidf("--outdir %s requires --archive since only the archive can be written to stdout", stdoutLocation)
+				}
+				if renderer.Format.IsMachineReadable() {
+					return invalidf("--outdir %s cannot be used with --output %s since both are written to stdout", stdoutLocation, renderer.Format)
+				}
+
+				// stdout is reserved for the archive, which requires a run without prompts, anything else being output to stderr
+				batch, quiet, noProgress = true, true, true
+				log.SetLevel(log.WarnLevel)
+				// the generator service still needs a directory name for the project within the archive
+				p.OutDir = ""
+				return create(cmd, &scaffoldResult{}, os.Stderr, os.Stdout)
+			}
+			if !renderer.Format.IsMachineReadable() {
+				return create(cmd, &scaffoldResult{}, os.Stdout, nil)
+			}
+
+			// machine-readable output requires a deterministic run without prompts nor informational output, what's left of the
+			// latter being kept apart from the machine-readable outcome so that it can still be parsed
+			batch, quiet, noProgress = true, true, true
+			log.SetLevel(log.WarnLevel)
+
+			result := &scaffoldResult{Project: p}
+			err = create(cmd, result, os.Stderr, nil)
+			result.Project = p.Redacted()
+			result.Success = err == nil
+			if err != nil {
+				result.Error = err.Error()
+			}
+			if renderErr := renderer.Render(result, nil, nil); renderErr != nil {
+				return renderErr
+			}
+			return err
+		},
+	}
+
+	createCmd.Flags().StringSliceVarP((*[]string)(&p.Template), "template", "t", nil, "Template name used to select the project to be created, cannot be used with --module. Can be repeated to combine templates if the generator service supports it")
+	createCmd.Flags().StringSliceVarP(&p.Modules, "module", "m", []string{}, "Spring Boot modules/starters, cannot be used with --template")
+	createCmd.Flags().StringVar(&category, "category", "", "Only offer the modules of the specified category when selecting modules interactively")
+	createCmd.Flags().StringSliceVar(&moduleList, "modules", []string{}, "Comma-separated Spring Boot modules/starters, e.g. web,actuator,jpa, combined with --module")
+	createCmd.Flags().StringVarP(&p.GroupId, "groupid", "g", "", "GroupId : com.example")
+	createCmd.Flags().StringVarP(&p.ArtifactId, "artifactid", "i", "", "ArtifactId: demo")
+	createCmd.Flags().StringVarP(&p.Version, "version", "v", "", "Version: 0.0.1-SNAPSHOT")
+	createCmd.Flags().StringVarP(&p.PackageName, "packagename", "p", "", "Package Name: com.example.demo")
+	createCmd.Flags().StringVarP(&p.SpringBootVersion, "springbootversion", "s", "", "Spring Boot Version, a prefix such as 2.7 selecting the single matching version")
+	createCmd.Flags().BoolVarP(&p.UseAp4k, "ap4k", "a", false, "Use ap4k when possible")
+	createCmd.Flags().BoolVarP(&p.UseSupported, "supported", "o", false, "Use supported version")
+	createCmd.Flags().BoolVar(&p.UseSupported, "use-supported-bom", false, "Use the supported Snowdrop BOM of the selected Spring Boot version without prompting, unless --snowdropbom is specified")
+	createCmd.Flags().StringVarP(&p.SnowdropBomVersion, "snowdropbom", "b", "", "Snowdrop BOM version, must match the selected Spring Boot version")
+	createCmd.Flags().StringVar(&p.BuildTool, "build", "maven", "Build system used by the generated project: "+strings.Join(buildTools, " or "))
+	createCmd.Flags().StringVar(&p.Packaging, "packaging", "jar", "Packaging of the generated project: "+strings.Join(packagings, " or "))
+	createCmd.Flags().StringVar(&p.JavaVersion, "java-version", "", "Java version targeted by the generated project, e.g. 11")
+	// -o being already used by --supported, -d (directory) is the shorthand of --outdir
+	createCmd.Flags().StringVarP(&p.OutDir, "outdir", "d", "", "Project location, either the name of a child directory of the current directory or an absolute path. "+
+		"Can contain variables such as {artifactId} or {version}, replaced by the corresponding project settings, in which case it can also be a nested path such as projects/{artifactId}. "+
+		"With --archive, - writes the archive to stdout")
+	createCmd.Flags().StringArrayVar(&parameters, "param", []string{}, "Additional key=value parameter passed as is to the generator service, can be repeated. "+
+		"Values of parameters whose key looks sensitive, e.g. contains password, secret or token, are redacted in exported specs and in the output")
+	createCmd.Flags().BoolVar(&force, "force", false, "Overwrite the content of the project location if it already exists")
+	createCmd.Flags().StringVar(&specFile, "from-spec", "", "YAML or JSON file describing the project to create without prompting, flags overriding its values")
+	createCmd.Flags().StringVar(&exportSpecFile, "export-spec", "", "YAML file to which the spec of the project is saved, even with --dry-run, to create it again using --from-spec")
+	createCmd.Flags().BoolVar(&batch, "batch", false, "Fail instead of prompting when required values are missing or invalid")
+	createCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Don't display progress while the project is being generated")
+	createCmd.Flags().StringVar(&outputFormat, "output", "", "Output format, either 'table', 'json' or 'yaml'. Machine-readable formats output the outcome of the creation and imply --batch")
+	createCmd.Flags().BoolVar(&archive, "archive", false, "Keep the generated project as <artifactid>.zip in the current directory instead of extracting it")
+	createCmd.Flags().BoolVar(&keepArchive, "keep-archive", false, "Keep the downloaded archive as <outdir>.zip alongside the extracted project")
+	createCmd.Flags().Var(&maxArchiveSize, "max-archive-size", "Maximum size of the generated archive, e.g. 500KB, 100MB or 1GB")
+	createCmd.Flags().Var(&limits.file, "max-file-size", "Maximum size of each file extracted from the generated archive")
+	createCmd.Flags().Var(&limits.total, "max-extracted-size", "Maximum total size of the files extracted from the generated archive")
+	createCmd.Flags().BoolVar(&keepPartial, "keep-partial", false, "Keep the partially extracted project if the extraction is interrupted")
+	createCmd.Flags().BoolVar(&open, "open", false, "Open the created project in the editor if any, in the file explorer otherwise")
+	createCmd.Flags().StringVar(&editor, "editor", "", "Editor command used by --open, defaults to $EDITOR")
+	createCmd.Flags().StringArrayVar(&postHooks, "post-hook", nil, "Shell command run in the created project once extracted, e.g. './mvnw compile'. Can be repeated, the commands being run in order until one fails. Only use trusted commands since they run with your privileges")
+	createCmd.Flags().BoolVar(&gitInit, "git-init", false, "Initialize a git repository with an initial commit in the created project")
+	createCmd.Flags().StringVar(&gitRemote, "git-remote", "", "URL of the origin remote to add to the git repository, requires --git-init")
+	createCmd.Flags().StringVar(&workDir, "work-dir", "", "Directory in which the project is created, the current directory by default")
+	createCmd.Flags().BoolVar(&verify, "verify", false, "Only check that the generator service produces a valid archive and list its entries, without creating the project")
+	createCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only display the request that would be sent to the service, without creating the project")
+
+	// flags shared with sub-commands
+	createCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "V", false, "Output debugging information")
+	createCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only output warnings and errors")
+	createCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format, either 'text' or 'json'")
+	createCmd.PersistentFlags().StringVar(&configFile, "config", "", "Configuration file providing default values (defaults to ~/.scaffoldrc)")
+	createCmd.PersistentFlags().StringVarP(&p.UrlService, "urlservice", "u", defaultServiceEndpoint(), "URL of the HTTP Server exposing the spring boot service (defaults to $"+serviceURLEnvVar+" if set)")
+	createCmd.PersistentFlags().StringVar(&p.ConfigPath, "config-endpoint", client.DefaultPaths.Config, "Path of the generator service configuration endpoint, relative to --urlservice")
+	createCmd.PersistentFlags().StringVar(&p.ModulesPath, "modules-endpoint", client.DefaultPaths.Modules, "Path of the generator service modules endpoint, relative to --urlservice. "+client.VersionPlaceholder+" is replaced by the Spring Boot version")
+	createCmd.PersistentFlags().StringVar(&p.AppPath, "app-endpoint", client.DefaultPaths.App, "Path of the generator service endpoint generating projects, relative to --urlservice")
+	createCmd.PersistentFlags().DurationVar(&p.Timeout, "timeout", 30*time.Second, "Timeout for connecting to the generator service and receiving the headers of its responses, downloads not being limited")
+	createCmd.PersistentFlags().StringVar(&p.Proxy, "proxy", "", "URL of the proxy to use to reach the generator service, overriding the HTTP_PROXY / HTTPS_PROXY environment variables")
+	createCmd.PersistentFlags().BoolVar(&p.Strict, "strict", false, "Fail instead of warning when the API version of the generator service isn't compatible with this version of scaffold")
+	createCmd.PersistentFlags().BoolVar(&p.Offline, "offline", false, "Use the generator service configuration cached during the last online run")
+	createCmd.PersistentFlags().DurationVar(&p.ConfigTTL, "config-ttl", 10*time.Minute, "How long the generator service configuration retrieved by a previous run is reused")
+	createCmd.PersistentFlags().BoolVar(&p.NoCache, "no-cache", false, "Always retrieve the generator service configuration instead of reusing a cached one")
+	createCmd.PersistentFlags().IntVar(&p.Retries, "retries", 3, "Number of times failed requests to the generator service are retried")
+	createCmd.PersistentFlags().StringVar(&p.CACert, "cacert", "", "PEM file containing additional CA certificates used to verify the generator service certificate")
+	createCmd.PersistentFlags().StringVar(&p.UserAgent, "user-agent", "snowdrop-scaffold/"+version, "User-Agent header sent to the generator service")
+	createCmd.PersistentFlags().StringVar(&p.Username, "username", "", "Username used to authenticate with the generator service using HTTP Basic Auth (a bearer token can be set with $"+tokenEnvVar+" instead)")
+	createCmd.PersistentFlags().StringVar(&p.Password, "password", "", "Password used to authenticate with the generator service using HTTP Basic Auth, requires --username")
+	createCmd.PersistentFlags().StringVar(&p.SaveRequest, "save-request", "", "File to which requests sent to the generator service and their responses are saved for debugging purposes")
+	createCmd.PersistentFlags().BoolVar(&p.Insecure, "insecure", false, "Skip verification of the generator service certificate, only use for testing")
+
+	createCmd.AddCommand(newListModulesCmd(ctx, p, generator))
+	createCmd.AddCommand(newListTemplatesCmd(ctx, p, generator))
+	createCmd.AddCommand(newListVersionsCmd(ctx, p, generator))
+	createCmd.AddCommand(newCompletionCmd())
+	createCmd.AddCommand(newDoctorCmd(ctx, p, generator))
+	// --version already sets the version of the generated project so the CLI version is only available as a sub-command
+	createCmd.AddCommand(newVersionCmd())
+
+	// dynamically complete values known by the generator service when using bash
+	createCmd.BashCompletionFunction = bashCompletionFunctions
+	createCmd.MarkFlagCustom("template", "__scaffold_list list-templates")
+	createCmd.MarkFlagCustom("module", "__scaffold_list list-modules")
+	createCmd.MarkFlagCustom("modules", "__scaffold_list list-modules")
+	createCmd.MarkFlagCustom("springbootversion", "__scaffold_list list-versions")
+	createCmd.MarkPersistentFlagFilename("config")
+	createCmd.MarkFlagFilename("from-spec", "yaml", "yml", "json")
+	createCmd.MarkFlagFilename("export-spec", "yaml", "yml")
+	createCmd.MarkPersistentFlagFilename("cacert", "pem", "crt")
+	createCmd.MarkPersistentFlagFilename("save-request")
+
+	// flag errors are reported like other invalid inputs
+	createCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return invalidInput(err)
+	})
+
+	err := createCmd.Execute()
+	if errors.Is(err, terminal.InterruptErr) {
+		// interrupting a prompt is equivalent to interrupting the process
+		err = client.ErrCancelled
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.Failure(os.Stderr, err.Error()))
+		os.Exit(exitCode(err))
+	}
+}
+
+// Exit codes of the process, depending on the class of the error that occurred
+const (
+	exitFailure    = 1
+	exitInvalid    = 2
+	exitNetwork    = 3
+	exitFilesystem = 4
+	// exitCancelled is the conventional exit code of processes interrupted by SIGINT
+	exitCancelled = 130
+)
+
+// invalidInputError reports invalid values, flags or combinations of flags provided by the user
+type invalidInputError struct {
+	error
+}
+
+func (e invalidInputError) Unwrap() error {
+	return e.error
+}
+
+// invalidf formats an error reporting an invalid input
+func invalidf(format string, args ...interface{}) error {
+	return invalidInputError{fmt.Errorf(format, args...)}
+}
+
+// invalidInput marks the specified error as reporting an invalid input
+func invalidInput(err error) error {
+	return invalidInputError{err}
+}
+
+// exitCode computes the exit code of the process reporting the specified error according to its class
+func exitCode(err error) int {
+	var invalid invalidInputError
+	var unavailable *client.ServiceUnavailableError
+	var badRequest *client.BadRequestError
+	var notFound *client.NotFoundError
+	var pathErr *os.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.Is(err, client.ErrCancelled), errors.Is(err, terminal.InterruptErr):
+		return exitCancelled
+	case errors.As(err, &invalid), errors.As(err, &badRequest), errors.As(err, &notFound):
+		return exitInvalid
+	case errors.As(err, &unavailable):
+		return exitNetwork
+	case errors.As(err, &pathErr), errors.As(err, &linkErr):
+		return exitFilesystem
+	}
+	return exitFailure
+}
+
+// interruptibleContext creates a context that is cancelled when the user interrupts the process (Ctrl-C). Subsequent interrupts
+// are not intercepted anymore so that the process can still be killed if cancellation takes too long.
+func interruptibleContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupted:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(interrupted)
+	}()
+	return ctx, cancel
+}
+
+// isPublicServiceEndpoint checks whether the specified generator service URL is the one of the public generator service
+func isPublicServiceEndpoint(url string) bool {
+	return strings.EqualFold(normalizeServiceURL(url), publicServiceEndpoint)
+}
+
+// defaultServiceEndpoint returns the generator service URL set by the SCAFFOLD_SERVICE_URL environment variable if any, the
+// compiled-in one otherwise
+func defaultServiceEndpoint() string {
+	if url := os.Getenv(serviceURLEnvVar); len(url) > 0 {
+		return normalizeServiceURL(url)
+	}
+	return normalizeServiceURL(ServiceEndpoint)
+}
+
+// normalizeServiceURL removes the trailing slashes of the specified generator service URL
+func normalizeServiceURL(url string) string {
+	return strings.TrimRight(url, "/")
+}
+
+// defaultConfigFile is the name of the configuration file looked up in the user's home directory
+const defaultConfigFile = ".scaffoldrc"
+
+// applyDefaults sets the values of the flags that were not explicitly set by the user to the defaults found in the specified
+// configuration file, or ~/.scaffoldrc if none is specified. A missing default configuration file is ignored.
+func applyDefaults(cmd *cobra.Command, configFile string) error {
+	explicit := len(configFile) > 0
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		configFile = filepath.Join(home, defaultConfigFile)
+	}
+
+	defaults, err := scaffold.LoadDefaults(configFile)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("couldn't read configuration file %s: %w", configFile, err)
+	}
+
+	for name, value := range defaults.AsFlags() {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || len(value) == 0 {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return invalidf("invalid value %s for %s in configuration file %s: %v", value, name, configFile, err)
+		}
+	}
+	return nil
+}
+
+// resolveConflicts makes sure that the specified modules don't conflict with each other according to the specified configuration,
+// failing in batch mode or letting the user choose which module to keep otherwise, using the specified selection function
+func resolveConflicts(c *scaffold.Config, modules []string, batch bool, choose func(string, []string, ...string) (string, error)) ([]string, error) {
+	for conflicts := c.GetConflicts(modules); len(conflicts) > 0; conflicts = c.GetConflicts(modules) {
+		if batch {
+			descriptions := make([]string, len(conflicts))
+			for i, conflict := range conflicts {
+				descriptions[i] = conflict.String()
+			}
+			return nil, invalidf("conflicting modules: %s", strings.Join(descriptions, ", "))
+		}
+
+		conflict := conflicts[0]
+		kept, err := choose(fmt.Sprintf("Modules %s conflict, which one do you want to keep?", conflict), []string{conflict.Module, conflict.With})
+		if err != nil {
+			return nil, err
+		}
+		removed := conflict.Module
+		if kept == conflict.Module {
+			removed = conflict.With
+		}
+		modules = removeElement(modules, removed)
+	}
+	return modules, nil
+}
 
-			p.GroupId = ui.Ask("Group Id", p.GroupId, "me.snowdrop")
-			p.ArtifactId = ui.Ask("Artifact Id", p.ArtifactId, "myproject")
-			p.Version = ui.Ask("Version", p.Version, "1.0.0-SNAPSHOT")
-			p.PackageName = ui.Ask("Package name", p.PackageName, p.GroupId+"."+p.ArtifactId)
-
-			currentDir, _ := os.Getwd()
-			p.OutDir = ui.Ask(fmt.Sprintf("Project location (immediate child directory of %s)", currentDir), p.OutDir)
-
-			client := http.Client{}
-
-			form := url.Values{}
-			form.Add("template", p.Template)
-			form.Add("groupid", p.GroupId)
-			form.Add("artifactid", p.ArtifactId)
-			form.Add("version", p.Version)
-			form.Add("packagename", p.PackageName)
-			form.Add("snowdropbom", p.SnowdropBomVersion)
-			form.Add("springbootversion", p.SpringBootVersion)
-			form.Add("outdir", p.OutDir)
-			form.Add("ap4k", strconv.FormatBool(p.UseAp4k))
-			for _, v := range p.Modules {
-				if v != "" {
-					form.Add("module", v)
+// removeElement returns a copy of the specified slice without the occurrences of the specified element
+func removeElement(slice []string, element string) []string {
+	result := make([]string, 0, len(slice))
+	for _, v := range slice {
+		if v != element {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// applySpec sets the values of the specified project that were not explicitly set using flags to the ones of the specified spec
+func applySpec(cmd *cobra.Command, p, spec *scaffold.Project) {
+	setString := func(flag string, value *string, specValue string) {
+		if !cmd.Flags().Changed(flag) && len(specValue) > 0 {
+			*value = specValue
+		}
+	}
+	setString("groupid", &p.GroupId, spec.GroupId)
+	setString("artifactid", &p.ArtifactId, spec.ArtifactId)
+	setString("version", &p.Version, spec.Version)
+	setString("packagename", &p.PackageName, spec.PackageName)
+	setString("outdir", &p.OutDir, spec.OutDir)
+	setString("build", &p.BuildTool, spec.BuildTool)
+	setString("java-version", &p.JavaVersion, spec.JavaVersion)
+	setString("packaging", &p.Packaging, spec.Packaging)
+	setString("snowdropbom", &p.SnowdropBomVersion, spec.SnowdropBomVersion)
+	setString("springbootversion", &p.SpringBootVersion, spec.SpringBootVersion)
+	setString("urlservice", &p.UrlService, spec.UrlService)
+
+	// template and modules being mutually exclusive, choosing either using flags overrides both in the spec
+	if !cmd.Flags().Changed("template") && !cmd.Flags().Changed("module") && !cmd.Flags().Changed("modules") {
+		p.Template, p.Modules = spec.Template, spec.Modules
+	}
+
+	if !cmd.Flags().Changed("ap4k") {
+		p.UseAp4k = spec.UseAp4k
+	}
+	if !supportedFlagChanged(cmd) {
+		p.UseSupported = spec.UseSupported
+	}
+}
+
+// baseDir returns the absolute path of the specified work directory in which projects are created, making sure it is a writable
+// directory, or the current directory if none is specified
+func baseDir(workDir string) (string, error) {
+	if len(workDir) == 0 {
+		return os.Getwd()
+	}
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", invalidInput(fmt.Errorf("invalid work directory %s: %w", workDir, err))
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", invalidInput(fmt.Errorf("invalid work directory: %w", err))
+	}
+	if !info.IsDir() {
+		return "", invalidf("invalid work directory: %s is not a directory", workDir)
+	}
+	probe, err := ioutil.TempFile(dir, ".scaffold-")
+	if err != nil {
+		return "", invalidInput(fmt.Errorf("work directory %s is not writable: %w", workDir, err))
+	}
+	probe.Close()
+	return dir, os.Remove(probe.Name())
+}
+
+// outDirVariable matches the variables of project locations, e.g. {artifactId}
+var outDirVariable = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// expandOutDir replaces the variables of the specified project location, e.g. {artifactId}, by the corresponding settings of the
+// specified project, ignoring case
+func expandOutDir(outDir string, p *scaffold.Project) (string, error) {
+	values := map[string]string{
+		"groupid":           p.GroupId,
+		"artifactid":        p.ArtifactId,
+		"version":           p.Version,
+		"packagename":       p.PackageName,
+		"springbootversion": p.SpringBootVersion,
+		"javaversion":       p.JavaVersion,
+		"packaging":         p.Packaging,
+	}
+
+	var unknown []string
+	expanded := outDirVariable.ReplaceAllStringFunc(outDir, func(variable string) string {
+		name := variable[1 : len(variable)-1]
+		value, ok := values[strings.ToLower(name)]
+		if !ok {
+			unknown = append(unknown, variable)
+		}
+		return value
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown variable(s) %s in project location %s, supported ones are: {groupId}, {artifactId}, {version}, "+
+			"{packageName}, {springBootVersion}, {javaVersion}, {packaging}", strings.Join(unknown, ", "), outDir)
+	}
+	if strings.ContainsAny(expanded, "{}") {
+		return "", fmt.Errorf("unbalanced braces in project location %s", outDir)
+	}
+	// only templated locations may be nested relative paths
+	validate := validation.ValidateOutDir
+	if outDirVariable.MatchString(outDir) {
+		validate = validation.ValidateExpandedOutDir
+	}
+	if err := validate(expanded); err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// projectDir computes the directory in which the project will be created, making sure it doesn't escape the current directory
+// unless an absolute path is explicitly specified
+func projectDir(currentDir, outDir string) (string, error) {
+	if err := validation.ValidateExpandedOutDir(outDir); err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(outDir) {
+		return filepath.Clean(outDir), nil
+	}
+	return filepath.Join(currentDir, outDir), nil
+}
+
+// isNonEmptyDir checks whether the specified path exists and is not an empty directory
+func isNonEmptyDir(path string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return true, nil
+	}
+
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return false, nil
+	}
+	return true, err
+}
+
+// missingRequiredFields returns the names of the flags that need to be provided for the specified project to be created without
+// prompting the user
+func missingRequiredFields(p *scaffold.Project) []string {
+	missing := make([]string, 0, 5)
+	if len(p.SpringBootVersion) == 0 {
+		missing = append(missing, "springbootversion")
+	}
+	if len(p.Template) == 0 && len(p.Modules) == 0 {
+		missing = append(missing, "template or module")
+	}
+	if len(p.GroupId) == 0 {
+		missing = append(missing, "groupid")
+	}
+	if len(p.ArtifactId) == 0 {
+		missing = append(missing, "artifactid")
+	}
+	if len(p.Version) == 0 {
+		missing = append(missing, "version")
+	}
+	return missing
+}
+
+// newListModulesCmd creates the list-modules sub-command, listing the modules compatible with a given Spring Boot version
+func newListModulesCmd(ctx context.Context, p *scaffold.Project, generator *client.Client) *cobra.Command {
+	var outputFormat, category string
+
+	listModulesCmd := &cobra.Command{
+		Use:   "list-modules [flags]",
+		Short: "List the available Spring Boot modules",
+		Long:  `List the Spring Boot modules available for the specified Spring Boot version or the default one if none is specified.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			renderer, err := newRenderer(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			c, err := getGeneratorServiceConfig(ctx, p, generator)
+			if err != nil {
+				return err
+			}
+			versions, defaultVersion, err := getBOMs(c)
+			if err != nil {
+				return err
+			}
+			if len(p.SpringBootVersion) > 0 {
+				if p.SpringBootVersion, err = resolveSpringBootVersion(p.SpringBootVersion, versions); err != nil {
+					return err
+				}
+			} else {
+				p.SpringBootVersion = defaultVersion
+				if len(p.SpringBootVersion) == 0 {
+					return fmt.Errorf("generator service doesn't define a default Spring Boot version, use --springbootversion")
 				}
 			}
 
-			parameters := form.Encode()
-			if parameters != "" {
-				parameters = "?" + parameters
+			modules, err := getCompatibleModulesFor(ctx, generator, p.SpringBootVersion)
+			if err != nil {
+				return err
+			}
+			if modules, err = filterModulesByCategory(modules, category); err != nil {
+				return err
+			}
+			sort.Slice(modules, func(i, j int) bool {
+				return modules[i].Name < modules[j].Name
+			})
+			rows := make([][]string, len(modules))
+			for i, module := range modules {
+				rows[i] = []string{module.Name, strings.Join(module.Tags, ","), module.Description}
+			}
+			return renderer.Render(modules, []string{"NAME", "CATEGORIES", "DESCRIPTION"}, rows)
+		},
+	}
+
+	listModulesCmd.Flags().StringVarP(&p.SpringBootVersion, "springbootversion", "s", "", "Spring Boot Version (defaults to the generator's default version)")
+	listModulesCmd.Flags().StringVar(&outputFormat, "output", "", "Output format, either 'table', 'json' or 'yaml'")
+	listModulesCmd.Flags().StringVar(&category, "category", "", "Only list the modules of the specified category")
+
+	return listModulesCmd
+}
+
+// filterModulesByCategory returns the specified modules belonging to the specified category, all of them if no category is
+// specified, failing if none does
+func filterModulesByCategory(modules []scaffold.Module, category string) ([]scaffold.Module, error) {
+	if len(category) == 0 {
+		return modules, nil
+	}
+	filtered := scaffold.FilterModulesByCategory(modules, category)
+	if len(filtered) == 0 {
+		return nil, invalidf("no module in category %s, available categories: %s", category, strings.Join(scaffold.GetCategoriesFor(modules), ", "))
+	}
+	return filtered, nil
+}
+
+// newListTemplatesCmd creates the list-templates sub-command, listing the templates known by the generator service
+func newListTemplatesCmd(ctx context.Context, p *scaffold.Project, generator *client.Client) *cobra.Command {
+	var outputFormat string
+
+	listTemplatesCmd := &cobra.Command{
+		Use:   "list-templates [flags]",
+		Short: "List the available project templates",
+		Long:  `List the project templates that can be used with the --template flag.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			renderer, err := newRenderer(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			c, err := getGeneratorServiceConfig(ctx, p, generator)
+			if err != nil {
+				return err
+			}
+
+			names := c.GetTemplateNames()
+			templates := c.GetTemplatesMap()
+			rows := make([][]string, len(names))
+			for i, name := range names {
+				rows[i] = []string{name, templates[name].Description}
+			}
+			return renderer.Render(names, []string{"NAME", "DESCRIPTION"}, rows)
+		},
+	}
+
+	listTemplatesCmd.Flags().StringVar(&outputFormat, "output", "", "Output format, either 'table', 'json' or 'yaml'")
+
+	return listTemplatesCmd
+}
+
+// bashCompletionFunctions provides the bash functions used to dynamically complete flag values, calling the list-* sub-commands
+const bashCompletionFunctions = `__scaffold_list()
+{
+    local out
+    if out=$(scaffold "$1" 2>/dev/null | awk 'NR > 1 {print $1}'); then
+        COMPREPLY=( $( compgen -W "${out[*]}" -- "$cur" ) )
+    fi
+}
+`
+
+// versionInfo describes the version of the running binary
+func versionInfo() string {
+	return fmt.Sprintf("scaffold %s (commit %s, built %s)", version, commit, date)
+}
+
+// newVersionCmd creates the version sub-command, outputting the version of the running binary
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Output the version of scaffold",
+		Long:  `Output the version of scaffold, along with the commit and date it was built from.`,
+		Args:  cobra.NoArgs,
+		// the parent command hooks check options irrelevant to this command
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(versionInfo())
+		},
+	}
+}
+
+// newCompletionCmd creates the completion sub-command, outputting the shell completion script for the specified shell
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh]",
+		Short: "Output shell completion code",
+		Long: `Output shell completion code for the specified shell, e.g.:
+
+  source <(scaffold completion bash)
+
+Only bash and zsh are supported: fish completion isn't available since the version of cobra used by scaffold (v0.0.3) has no
+fish completion generator.`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletion(os.Stdout)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(os.Stdout)
+			case "fish":
+				return invalidf("fish completion is not supported: cobra v0.0.3, used by scaffold, has no fish completion generator, " +
+					"supported shells are: bash, zsh")
+			default:
+				return invalidf("unsupported shell '%s', supported ones are: bash, zsh", args[0])
+			}
+		},
+	}
+}
+
+// scaffoldResult is the machine-readable representation of the outcome of the project creation
+type scaffoldResult struct {
+	Project *scaffold.Project `json:"project"`
+	URL     string            `json:"url,omitempty"`
+	Dir     string            `json:"dir,omitempty"`
+	Archive string            `json:"archive,omitempty"`
+	Entries []string          `json:"entries,omitempty"`
+	Hooks   []hookResult      `json:"hooks,omitempty"`
+	Success bool              `json:"success"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// springBootVersion is the machine-readable representation of a Spring Boot version supported by the generator service
+type springBootVersion struct {
+	Version string `json:"version"`
+	Default bool   `json:"default"`
+}
+
+// newListVersionsCmd creates the list-versions sub-command, listing the Spring Boot versions supported by the generator service
+func newListVersionsCmd(ctx context.Context, p *scaffold.Project, generator *client.Client) *cobra.Command {
+	var outputFormat string
+
+	listVersionsCmd := &cobra.Command{
+		Use:   "list-versions [flags]",
+		Short: "List the supported Spring Boot versions",
+		Long:  `List the Spring Boot versions that can be used with the --springbootversion flag, marking the default one.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			renderer, err := newRenderer(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			c, err := getGeneratorServiceConfig(ctx, p, generator)
+			if err != nil {
+				return err
 			}
 
-			u := strings.Join([]string{p.UrlService, "app"}, "/") + parameters
-			log.Infof("URL of the request calling the service is %s", u)
-			req, err := http.NewRequest(http.MethodGet, u, strings.NewReader(""))
+			boms, defaultVersion, err := getBOMs(c)
+			if err != nil {
+				return err
+			}
+			versions := scaffold.GetSpringBootVersions(boms)
+			result := make([]springBootVersion, len(versions))
+			rows := make([][]string, len(versions))
+			for i, v := range versions {
+				result[i] = springBootVersion{Version: v, Default: v == defaultVersion}
+				rows[i] = []string{v, boms[v].Snowdrop, boms[v].Supported, ""}
+				if v == defaultVersion {
+					rows[i][3] = "yes"
+				}
+			}
+			return renderer.Render(result, []string{"VERSION", "SNOWDROP BOM", "SUPPORTED BOM", "DEFAULT"}, rows)
+		},
+	}
 
+	listVersionsCmd.Flags().StringVar(&outputFormat, "output", "", "Output format, either 'table', 'json' or 'yaml'")
+
+	return listVersionsCmd
+}
+
+// newDoctorCmd creates the doctor sub-command, diagnosing the issues preventing projects from being created
+func newDoctorCmd(ctx context.Context, p *scaffold.Project, generator *client.Client) *cobra.Command {
+	var outputFormat string
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose connectivity and environment issues",
+		Long: `Check that the generator service can be resolved, reached and returns a valid configuration, output the proxy in
+effect and check that projects can be created in the current directory. Exits with a non-zero status if a critical check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			renderer, err := newRenderer(outputFormat)
 			if err != nil {
 				return err
 			}
-			addClientHeader(req)
+			diagnoses := diagnose(ctx, p, generator)
+			if !renderer.Format.IsMachineReadable() {
+				return printDiagnoses(renderer.Out, diagnoses)
+			}
+
+			results := make([]checkResult, len(diagnoses))
+			for i, d := range diagnoses {
+				results[i] = checkResult{Check: d.check, Status: d.status(), Detail: d.detail}
+				if d.err != nil {
+					results[i].Error = d.err.Error()
+				}
+			}
+			if err := renderer.Render(results, nil, nil); err != nil {
+				return err
+			}
+			return criticalFailures(diagnoses)
+		},
+	}
+
+	doctorCmd.Flags().StringVar(&outputFormat, "output", "", "Output format, either 'table', 'json' or 'yaml'")
+
+	return doctorCmd
+}
+
+// checkResult is the machine-readable representation of a check performed by the doctor sub-command
+type checkResult struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// diagnosis is the result of a check performed by the doctor sub-command
+type diagnosis struct {
+	check string
+	// detail provides additional information about a successful check
+	detail string
+	err    error
+	// critical indicates whether projects cannot be created if the check fails
+	critical bool
+}
+
+// diagnose checks the environment in which projects are created using the specified generator service
+func diagnose(ctx context.Context, p *scaffold.Project, generator *client.Client) []diagnosis {
+	diagnoses := []diagnosis{}
+	serviceURL, err := url.Parse(generator.URL)
+	if err != nil {
+		return append(diagnoses, diagnosis{check: "Generator service URL", err: err, critical: true})
+	}
+
+	resolution := diagnosis{check: "DNS resolution of " + serviceURL.Hostname(), critical: true}
+	if net.ParseIP(serviceURL.Hostname()) != nil {
+		resolution.detail = "IP address"
+	} else {
+		var addresses []string
+		if addresses, resolution.err = net.DefaultResolver.LookupHost(ctx, serviceURL.Hostname()); resolution.err == nil {
+			resolution.detail = strings.Join(addresses, ", ")
+		}
+	}
+	diagnoses = append(diagnoses, resolution)
+
+	proxy := diagnosis{check: "Proxy"}
+	if len(p.Proxy) > 0 {
+		proxy.detail = p.Proxy + " (--proxy)"
+	} else if req, err := http.NewRequest(http.MethodGet, generator.URL, nil); err != nil {
+		proxy.err = err
+	} else if proxyURL, err := http.ProxyFromEnvironment(req); err != nil {
+		proxy.err = err
+	} else if proxyURL != nil {
+		proxy.detail = proxyURL.Redacted() + " (environment)"
+	} else {
+		proxy.detail = "none"
+	}
+	diagnoses = append(diagnoses, proxy)
+
+	// failing to parse the configuration is reported separately since the service was reached in that case
+	reachability := diagnosis{check: "Reachability of " + generator.ConfigURL(), critical: true}
+	parsing := diagnosis{check: "Generator service configuration", critical: true}
+	c, err := generator.GetConfig(ctx)
+	var unavailable *client.ServiceUnavailableError
+	var badRequest *client.BadRequestError
+	var notFound *client.NotFoundError
+	switch {
+	case errors.As(err, &unavailable), errors.As(err, &badRequest), errors.As(err, &notFound), err == client.ErrCancelled:
+		reachability.err = err
+		parsing.err = errors.New("skipped since the generator service couldn't be reached")
+	case err != nil:
+		parsing.err = err
+	default:
+		if parsing.err = c.Validate(); parsing.err == nil {
+			parsing.detail = fmt.Sprintf("%d templates, %d Spring Boot versions", len(c.Templates), len(c.Boms))
+		}
+	}
+	diagnoses = append(diagnoses, reachability, parsing)
+
+	// an incompatible API version only prevents projects from being created in strict mode
+	if c != nil {
+		compatibility := diagnosis{check: "Generator service API version", critical: p.Strict, detail: "not reported"}
+		if len(c.APIVersion) > 0 {
+			compatibility.detail = c.APIVersion
+			compatibility.err = client.CheckAPIVersion(c.APIVersion)
+		}
+		diagnoses = append(diagnoses, compatibility)
+	}
+
+	writable := diagnosis{check: "Write permissions in the current directory", critical: true}
+	writable.detail, writable.err = baseDir(".")
+	return append(diagnoses, writable)
+}
+
+// status returns whether the check passed, failed or only warrants a warning since it isn't critical
+func (d diagnosis) status() string {
+	switch {
+	case d.err == nil:
+		return "pass"
+	case d.critical:
+		return "fail"
+	default:
+		return "warn"
+	}
+}
+
+// printDiagnoses outputs the specified diagnoses as a checklist, returning an error if a critical check failed
+func printDiagnoses(out io.Writer, diagnoses []diagnosis) error {
+	for _, d := range diagnoses {
+		switch {
+		case d.err == nil && len(d.detail) > 0:
+			fmt.Fprintf(out, "%s %s: %s\n", ui.Success(out, "[PASS]"), d.check, d.detail)
+		case d.err == nil:
+			fmt.Fprintf(out, "%s %s\n", ui.Success(out, "[PASS]"), d.check)
+		case d.critical:
+			fmt.Fprintf(out, "%s %s: %v\n", ui.Failure(out, "[FAIL]"), d.check, d.err)
+		default:
+			fmt.Fprintf(out, "%s %s: %v\n", ui.Warning(out, "[WARN]"), d.check, d.err)
+		}
+	}
+	return criticalFailures(diagnoses)
+}
+
+// criticalFailures returns an error if a critical check of the specified diagnoses failed
+func criticalFailures(diagnoses []diagnosis) error {
+	failed := 0
+	for _, d := range diagnoses {
+		if d.status() == "fail" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d critical check(s) failed", failed)
+	}
+	return nil
+}
 
-			res, err := client.Do(req)
-			if err != nil {
-				return err
-			}
-			body, err := ioutil.ReadAll(res.Body)
-			if err != nil {
-				return err
-			}
+const (
+	createProjectChoice = "Create project"
+	abortChoice         = "Abort"
+)
 
-			dir := filepath.Join(currentDir, p.OutDir)
-			zipFile := dir + ".zip"
+// reviewProject displays the settings used to create the specified project in the specified location and lets the user edit
+// them, including the unexpanded project location outDir, using the specified selection and input functions until they either
+// confirm, in which case true is returned, or abort. The location is computed again using locate after each edit, since it
+// might depend on the edited settings.
+func reviewProject(out io.Writer, p *scaffold.Project, location string, outDir *string, locate func() (string, error),
+	choose func(string, []string, ...string) (string, error), ask func(string, string, validation.Validator, ...string) (string, error)) (bool, error) {
+	fields := []struct {
+		name      string
+		value     *string
+		validator validation.Validator
+	}{
+		{name: "Group Id", value: &p.GroupId, validator: validation.GroupIdValidator},
+		{name: "Artifact Id", value: &p.ArtifactId, validator: validation.ArtifactIdValidator},
+		{name: "Version", value: &p.Version, validator: validation.VersionValidator},
+		{name: "Package name", value: &p.PackageName, validator: validation.PackageNameValidator},
+		{name: "Project location", value: outDir, validator: validation.OutDirValidator},
+	}
+	choices := []string{createProjectChoice}
+	for _, field := range fields {
+		choices = append(choices, "Edit "+field.name)
+	}
+	choices = append(choices, abortChoice)
 
-			err = ioutil.WriteFile(zipFile, body, 0644)
-			if err != nil {
-				return fmt.Errorf("failed to download file %s due to %s", zipFile, err)
-			}
-			err = Unzip(zipFile, dir)
-			if err != nil {
-				return fmt.Errorf("failed to unzip new project file %s due to %s", zipFile, err)
-			}
-			err = os.Remove(zipFile)
-			if err != nil {
-				return err
+	for {
+		printSummary(out, p, location)
+		choice, err := choose("Create project with these settings or edit them?", choices, createProjectChoice)
+		if err != nil {
+			return false, err
+		}
+		switch choice {
+		case createProjectChoice:
+			return true, nil
+		case abortChoice:
+			return false, nil
+		}
+		for _, field := range fields {
+			if choice == "Edit "+field.name {
+				if *field.value, err = ask(field.name, "", field.validator, *field.value); err != nil {
+					return false, err
+				}
+				if location, err = locate(); err != nil {
+					return false, err
+				}
 			}
-			return nil
-		},
+		}
 	}
+}
 
-	createCmd.Flags().StringVarP(&p.Template, "template", "t", "", "Template name used to select the project to be created")
-	createCmd.Flags().StringVarP(&p.UrlService, "urlservice", "u", ServiceEndpoint, "URL of the HTTP Server exposing the spring boot service")
-	createCmd.Flags().StringSliceVarP(&p.Modules, "module", "m", []string{}, "Spring Boot modules/starters")
-	createCmd.Flags().StringVarP(&p.GroupId, "groupid", "g", "", "GroupId : com.example")
-	createCmd.Flags().StringVarP(&p.ArtifactId, "artifactid", "i", "", "ArtifactId: demo")
-	createCmd.Flags().StringVarP(&p.Version, "version", "v", "", "Version: 0.0.1-SNAPSHOT")
-	createCmd.Flags().StringVarP(&p.PackageName, "packagename", "p", "", "Package Name: com.example.demo")
-	createCmd.Flags().StringVarP(&p.SpringBootVersion, "springbootversion", "s", "", "Spring Boot Version")
-	createCmd.Flags().BoolVarP(&p.UseAp4k, "ap4k", "a", false, "Use ap4k when possible")
-	createCmd.Flags().BoolVarP(&p.UseSupported, "supported", "o", false, "Use supported version")
+// printSummary outputs the settings used to create the specified project in the specified directory
+func printSummary(out io.Writer, p *scaffold.Project, dir string) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Spring Boot version:\t"+p.SpringBootVersion)
+	fmt.Fprintln(w, "Snowdrop BOM version:\t"+p.SnowdropBomVersion)
+	if len(p.Template) > 0 {
+		fmt.Fprintln(w, "Template:\t"+p.Template.String())
+	} else {
+		fmt.Fprintln(w, "Modules:\t"+strings.Join(p.Modules, ", "))
+	}
+	fmt.Fprintf(w, "Coordinates:\t%s:%s:%s\n", p.GroupId, p.ArtifactId, p.Version)
+	fmt.Fprintln(w, "Package name:\t"+p.PackageName)
+	fmt.Fprintln(w, "Build system:\t"+p.BuildTool)
+	fmt.Fprintln(w, "Packaging:\t"+p.Packaging)
+	if len(p.JavaVersion) > 0 {
+		fmt.Fprintln(w, "Java version:\t"+p.JavaVersion)
+	}
+	fmt.Fprintln(w, "Location:\t"+dir)
+	w.Flush()
+}
 
-	err := createCmd.Execute()
+// newRenderer returns a renderer outputting to stdout in the specified format, the table one if none is specified
+func newRenderer(format string) (output.Renderer, error) {
+	f, err := output.ParseFormat(format)
 	if err != nil {
-		fmt.Print(err.Error())
+		return output.Renderer{}, invalidInput(err)
 	}
+	return output.Renderer{Out: os.Stdout, Format: f}, nil
 }
 
 type svcInstance struct {
@@ -358,101 +1767,701 @@ func GetMatchingPlans(serviceCatalogClient *servicecatalogclienset.Servicecatalo
 	return plans, err
 }
 
-func getYamlFrom(url, endpoint string, result interface{}) {
-	// Call the /config endpoint to get the configuration
-	URL := strings.Join([]string{url, endpoint}, "/")
-	client := http.Client{}
+// getGeneratorServiceConfig retrieves the generator service configuration using the specified client, warning if its API version
+// isn't compatible with this version of scaffold, or failing in strict mode
+func getGeneratorServiceConfig(ctx context.Context, p *scaffold.Project, generator *client.Client) (*scaffold.Config, error) {
+	c, err := loadGeneratorServiceConfig(ctx, p, generator)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.APIVersion) == 0 {
+		log.Debug("Generator service doesn't report its API version, assuming it is compatible")
+	} else if err := client.CheckAPIVersion(c.APIVersion); err != nil && p.Strict {
+		return nil, err
+	} else if err != nil {
+		log.Warnf("%v (use --strict to fail instead)", err)
+	}
+	return c, nil
+}
+
+// loadGeneratorServiceConfig retrieves the generator service configuration using the specified client, caching it for later offline
+// use. In offline mode, the cached configuration is used instead.
+func loadGeneratorServiceConfig(ctx context.Context, p *scaffold.Project, generator *client.Client) (*scaffold.Config, error) {
+	// the configuration is cached per endpoint, services possibly exposing several versions of their API
+	cachePath, cacheErr := scaffold.ConfigCachePath(generator.ConfigURL())
+	if p.Offline {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("couldn't determine configuration cache location: %v", cacheErr)
+		}
+		cached, err := scaffold.LoadConfig(cachePath)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no cached configuration found at %s, run once without --offline to create it", cachePath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read cached configuration %s: %w", cachePath, err)
+		}
+		if err := cached.Config.Validate(); err != nil {
+			return nil, fmt.Errorf("cached configuration %s is invalid, run once without --offline to refresh it: %v", cachePath, err)
+		}
+		return cached.Config, nil
+	}
 
-	req, err := http.NewRequest(http.MethodGet, URL, strings.NewReader(""))
+	// use the configuration cached by a recent run if possible
+	if cacheErr == nil && !p.NoCache && p.ConfigTTL > 0 {
+		cached, err := scaffold.LoadConfig(cachePath)
+		if err == nil && cached.IsFresh(p.ConfigTTL) {
+			// an invalid cached configuration, e.g. saved by an older version, is replaced by a freshly retrieved one
+			if err := cached.Config.Validate(); err != nil {
+				log.Debugf("Ignoring cached generator service configuration: %v", err)
+			} else {
+				log.Debugf("Using generator service configuration cached at %s", cached.FetchedAt)
+				return cached.Config, nil
+			}
+		}
+	}
 
+	c, err := generator.GetConfig(ctx)
 	if err != nil {
-		log.Error(err.Error())
+		return nil, fmt.Errorf("couldn't retrieve generator service configuration: %w", err)
 	}
-	addClientHeader(req)
+	// fail with a clear message rather than later on because of missing values, and don't cache an invalid configuration
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cacheErr == nil {
+		cacheErr = scaffold.SaveConfig(cachePath, c)
+	}
+	if cacheErr != nil {
+		log.Debugf("Couldn't cache generator service configuration: %v", cacheErr)
+	}
+	return c, nil
+}
 
-	res, err := client.Do(req)
+// getCompatibleModulesFor retrieves the modules compatible with the specified Spring Boot version using the specified client
+func getCompatibleModulesFor(ctx context.Context, generator *client.Client, springBootVersion string) ([]scaffold.Module, error) {
+	modules, err := generator.GetModules(ctx, springBootVersion)
 	if err != nil {
-		log.Error(err.Error())
+		return nil, fmt.Errorf("couldn't retrieve modules for Spring Boot %s: %w", springBootVersion, err)
+	}
+	return modules, nil
+}
+
+// moduleFetcher retrieves the modules compatible with Spring Boot versions in the background, so that they are already available
+// when needed, remembering them so that they are only retrieved once per version
+type moduleFetcher struct {
+	ctx       context.Context
+	generator *client.Client
+	mutex     sync.Mutex
+	fetches   map[string]*moduleFetch
+}
+
+// moduleFetch is the, possibly ongoing, retrieval of the modules compatible with a given Spring Boot version
+type moduleFetch struct {
+	done    chan struct{}
+	modules []scaffold.Module
+	err     error
+}
+
+// newModuleFetcher creates a moduleFetcher using the specified generator service client until ctx is cancelled
+func newModuleFetcher(ctx context.Context, generator *client.Client) *moduleFetcher {
+	return &moduleFetcher{ctx: ctx, generator: generator, fetches: make(map[string]*moduleFetch)}
+}
+
+// prefetch starts retrieving the modules compatible with the specified Spring Boot version unless it has already been done
+func (f *moduleFetcher) prefetch(springBootVersion string) *moduleFetch {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	fetch, ok := f.fetches[springBootVersion]
+	if !ok {
+		fetch = &moduleFetch{done: make(chan struct{})}
+		f.fetches[springBootVersion] = fetch
+		go func() {
+			defer close(fetch.done)
+			fetch.modules, fetch.err = getCompatibleModulesFor(f.ctx, f.generator, springBootVersion)
+		}()
+	}
+	return fetch
+}
+
+// modules returns the modules compatible with the specified Spring Boot version, waiting for them to be retrieved if needed
+func (f *moduleFetcher) modules(springBootVersion string) ([]scaffold.Module, error) {
+	fetch := f.prefetch(springBootVersion)
+	select {
+	case <-fetch.done:
+		return fetch.modules, fetch.err
+	case <-f.ctx.Done():
+		return nil, client.ErrCancelled
 	}
-	body, err := ioutil.ReadAll(res.Body)
+}
+
+// moduleNames returns the sorted names of the modules compatible with the specified Spring Boot version
+func (f *moduleFetcher) moduleNames(springBootVersion string) ([]string, error) {
+	modules, err := f.modules(springBootVersion)
 	if err != nil {
-		log.Error(err.Error())
+		return nil, err
+	}
+	return scaffold.GetModuleNamesFor(modules), nil
+}
+
+// withReleaseSuffix adds the release suffix to the specified Spring Boot version if needed since we allow 2.1.3 instead of the
+// full 2.1.3.RELEASE
+func withReleaseSuffix(springBootVersion string) string {
+	if !strings.HasSuffix(springBootVersion, ReleaseSuffix) {
+		return springBootVersion + ReleaseSuffix
+	}
+	return springBootVersion
+}
+
+// resolveSpringBootVersion returns the version amongst the specified BOMs identified by the specified Spring Boot version, either
+// exactly, without its release suffix or as a prefix matching a single version, e.g. 2.7 for 2.7.18. An ambiguous prefix is an
+// invalid input while an unknown version is returned with the release suffix so that it is reported as such.
+func resolveSpringBootVersion(springBootVersion string, boms map[string]scaffold.Bom) (string, error) {
+	if _, ok := boms[springBootVersion]; ok {
+		return springBootVersion, nil
+	}
+	withSuffix := withReleaseSuffix(springBootVersion)
+	if _, ok := boms[withSuffix]; ok {
+		return withSuffix, nil
 	}
 
-	if strings.Contains(string(body), "Application is not available") {
-		log.Fatal("Generator service is not available")
+	candidates := []string{}
+	for _, version := range scaffold.GetSpringBootVersions(boms) {
+		if strings.HasPrefix(version, springBootVersion+".") {
+			candidates = append(candidates, version)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return withReleaseSuffix(springBootVersion), nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", invalidf("Spring Boot version %s is ambiguous, use one of: %s", springBootVersion, strings.Join(candidates, ", "))
 	}
+}
+
+// extractProject downloads the zipped project from the specified content and extracts it into the specified directory, removing
+// the downloaded zip file unless keep is true and the project was successfully extracted
+func extractProject(ctx context.Context, content io.Reader, dir string, keep bool, limits extractionLimits) (err error) {
+	zipFile := dir + ".zip"
+	defer func() {
+		// the archive is only kept if the project was successfully extracted
+		if keep && err == nil {
+			return
+		}
+		if removeErr := os.Remove(zipFile); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+			err = removeErr
+		}
+	}()
 
-	err = yaml.Unmarshal(body, &result)
+	// the project might be created in a nested directory which doesn't exist yet
+	if err = os.MkdirAll(filepath.Dir(dir), defaultDirMode); err != nil {
+		return err
+	}
+	err = download(content, zipFile)
+	if err != nil {
+		return fmt.Errorf("failed to download file %s due to %w", zipFile, err)
+	}
+	err = unzip(ctx, zipFile, dir, limits)
 	if err != nil {
-		log.Fatal(err.Error())
+		return fmt.Errorf("failed to unzip new project file %s due to %w", zipFile, err)
+	}
+	return nil
+}
+
+// cleanupPartialProject removes the project partially extracted in the specified directory when the extraction is interrupted,
+// unless keep is true or the directory wasn't created by the extraction, in which case it might contain other files
+func cleanupPartialProject(dir string, created, keep bool) {
+	switch {
+	case keep:
+		log.Warnf("Partially extracted project kept in %s", dir)
+	case !created:
+		log.Warnf("%s already existed and might contain a partially extracted project", dir)
+	default:
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warnf("Couldn't remove partially extracted project %s: %v", dir, err)
+			return
+		}
+		fmt.Fprintln(os.Stderr, ui.Warning(os.Stderr, fmt.Sprintf("Removed partially extracted project %s", dir)))
 	}
 }
 
-func getGeneratorServiceConfig(url string) *scaffold.Config {
-	c := &scaffold.Config{}
-	getYamlFrom(url, "config", c)
+// verifyArchive checks that the specified zipped project content is a valid archive, using a temporary file which is removed
+// afterwards, and returns the names of its entries
+func verifyArchive(content io.Reader) ([]string, error) {
+	tmp, err := ioutil.TempFile("", "scaffold-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err = download(content, tmp.Name()); err != nil {
+		return nil, fmt.Errorf("failed to download archive due to %w", err)
+	}
+
+	r, err := zip.OpenReader(tmp.Name())
+	if err != nil {
+		return nil, fmt.Errorf("generated archive is not valid: %w", err)
+	}
+	defer r.Close()
 
-	return c
+	entries := make([]string, len(r.File))
+	for i, f := range r.File {
+		entries[i] = f.Name
+	}
+	return entries, nil
 }
 
-func getCompatibleModuleNamesFor(p *scaffold.Project) []string {
-	modules := &[]scaffold.Module{}
-	getYamlFrom(p.UrlService, "modules/"+p.SpringBootVersion, modules)
-	return scaffold.GetModuleNamesFor(*modules)
+// stdoutLocation is the project location streaming the archive to stdout
+const stdoutLocation = "-"
+
+// saveArchive saves the specified zipped project content to the specified path, removing the partially written file on failure
+func saveArchive(content io.Reader, path string) error {
+	if err := download(content, path); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to download file %s due to %w", path, err)
+	}
+	return nil
 }
 
-func addClientHeader(req *http.Request) {
-	userAgent := "snowdrop-scaffold/1.0"
-	req.Header.Set("User-Agent", userAgent)
+// download streams the specified content to the file at the specified path
+func download(content io.Reader, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	written, err := io.Copy(out, content)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	log.Debugf("Downloaded %d bytes to %s", written, path)
+	return out.Close()
 }
 
 func Unzip(src, dest string) error {
+	return unzip(context.Background(), src, dest, defaultExtractionLimits)
+}
+
+// extractionLimits bound the size of extracted content, protecting against decompression bombs, i.e. archives with small
+// compressed entries expanding to huge files
+type extractionLimits struct {
+	// file is the maximum size of each extracted file
+	file byteSize
+	// total is the maximum total size of the extracted files
+	total byteSize
+}
+
+var defaultExtractionLimits = extractionLimits{file: 100 << 20, total: 500 << 20}
+
+// unzip extracts the archive at the specified path in the specified destination directory, within the specified limits,
+// stopping as soon as the specified context is cancelled
+func unzip(ctx context.Context, src, dest string, limits extractionLimits) error {
 	r, err := zip.OpenReader(src)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
 
+	var extracted int64
 	for _, f := range r.File {
-		rc, err := f.Open()
+		if ctx.Err() != nil {
+			return client.ErrCancelled
+		}
+		limit, limitErr := int64(limits.file), fmt.Errorf("archive entry %s exceeds the maximum extracted file size of %s", f.Name, limits.file)
+		if remaining := int64(limits.total) - extracted; remaining < limit {
+			limit, limitErr = remaining, fmt.Errorf("extracting archive entry %s exceeds the maximum total extracted size of %s", f.Name, limits.total)
+		}
+		written, err := extractFile(f, dest, limit, limitErr)
 		if err != nil {
 			return err
 		}
-		defer rc.Close()
+		extracted += written
+	}
+	return nil
+}
 
-		name := filepath.Join(dest, f.Name)
-		if f.FileInfo().IsDir() {
-			err := os.MkdirAll(name, os.ModePerm)
-			if err != nil {
-				return err
-			}
-		} else {
-			var fdir string
-			if lastIndex := strings.LastIndex(name, string(os.PathSeparator)); lastIndex > -1 {
-				fdir = name[:lastIndex]
-			}
+// extractFile extracts the specified archive entry in the specified destination directory, failing with limitErr if it is
+// larger than limit bytes, and returns the number of bytes written. File handles are released before returning so that
+// extracting large archives doesn't exhaust file descriptors.
+func extractFile(f *zip.File, dest string, limit int64, limitErr error) (int64, error) {
+	name := filepath.Join(dest, f.Name)
+	// make sure that the archive cannot write outside of dest (Zip Slip)
+	cleanDest := filepath.Clean(dest)
+	if name != cleanDest && !strings.HasPrefix(name, cleanDest+string(os.PathSeparator)) {
+		return 0, fmt.Errorf("illegal file path in archive: %s", f.Name)
+	}
+	if f.FileInfo().IsDir() {
+		// create the directory even if empty, with the permissions recorded in the archive
+		mode := dirMode(f.Mode())
+		err := os.MkdirAll(name, mode)
+		if err != nil {
+			return 0, err
+		}
+		// the directory might already exist if it was created as the parent of a previous entry
+		return 0, os.Chmod(name, mode)
+	}
 
-			err = os.MkdirAll(fdir, os.ModePerm)
-			if err != nil {
-				return err
-			}
-			f, err := os.OpenFile(
-				name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-			if err != nil {
-				return err
-			}
-			defer f.Close()
+	if f.UncompressedSize64 > uint64(limit) {
+		return 0, limitErr
+	}
 
-			_, err = io.Copy(f, rc)
-			if err != nil {
-				return err
-			}
+	err := os.MkdirAll(filepath.Dir(name), defaultDirMode)
+	if err != nil {
+		return 0, err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return 0, err
+	}
+
+	// the size recorded in the archive cannot be trusted so the extracted content is limited as well
+	written, err := io.Copy(out, &limitedReader{reader: rc, remaining: limit, err: limitErr})
+	if err != nil {
+		out.Close()
+		return written, err
+	}
+	return written, out.Close()
+}
+
+// limitedReader reads at most remaining bytes from the underlying reader, failing with err if it has more content
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	err       error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// only fail if there actually is more content
+		var b [1]byte
+		n, err := l.reader.Read(b[:])
+		if n > 0 {
+			return 0, l.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// byteSize is a number of bytes which can be set using a unit, e.g. 100MB, units being powers of 1024
+type byteSize int64
+
+// sizeUnits are the units of byte sizes, from the largest one
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+func (s *byteSize) Set(value string) error {
+	number, multiplier := strings.ToUpper(strings.TrimSpace(value)), int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number, multiplier = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix)), unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid size %s, expected a positive number optionally followed by KB, MB or GB", value)
+	}
+	*s = byteSize(n * multiplier)
+	return nil
+}
+
+func (s byteSize) String() string {
+	for _, unit := range sizeUnits {
+		if int64(s) >= unit.multiplier && int64(s)%unit.multiplier == 0 {
+			return strconv.FormatInt(int64(s)/unit.multiplier, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(int64(s), 10) + "B"
+}
+
+func (s *byteSize) Type() string {
+	return "size"
+}
+
+// defaultDirMode is used for directories that are not explicitly present in archives
+const defaultDirMode os.FileMode = 0755
+
+// dirMode computes the permissions to use for a directory entry with the specified mode, falling back to defaultDirMode if the
+// archive didn't record usable permissions
+func dirMode(mode os.FileMode) os.FileMode {
+	perm := mode.Perm()
+	if perm&0700 != 0700 {
+		return defaultDirMode
+	}
+	return perm
+}
+
+// initGitRepository initializes a git repository in the specified directory, committing its content and adding the specified
+// remote as origin if any
+func initGitRepository(dir, remote string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed")
+	}
+
+	commands := [][]string{
+		{"init"},
+		{"add", "--all"},
+		{"commit", "--quiet", "--message", "Initial commit"},
+	}
+	if len(remote) > 0 {
+		commands = append(commands, []string{"remote", "add", "origin", remote})
+	}
+
+	for _, args := range commands {
+		git := exec.Command("git", args...)
+		git.Dir = dir
+		out, err := git.CombinedOutput()
+		log.Debugf("git %s: %s", strings.Join(args, " "), out)
+		if err != nil {
+			return fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(string(out)))
 		}
 	}
 	return nil
 }
 
+// hookResult is the machine-readable representation of the outcome of a post-generation hook
+type hookResult struct {
+	Command  string `json:"command"`
+	ExitCode int    `json:"exitcode"`
+}
+
+// shellCommand returns the command running the specified command line using the shell of the specified platform
+func shellCommand(ctx context.Context, command, goos string) *exec.Cmd {
+	if goos == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runPostHooks runs the specified shell commands in order in the specified project directory, streaming their output to the
+// specified writers, and stops at the first one which fails, returning the outcome of the commands that were run
+func runPostHooks(ctx context.Context, dir string, hooks []string, stdout, stderr io.Writer) ([]hookResult, error) {
+	results := make([]hookResult, 0, len(hooks))
+	for _, hook := range hooks {
+		log.Infof("Running post-generation hook: %s", hook)
+		cmd := shellCommand(ctx, hook, runtime.GOOS)
+		cmd.Dir, cmd.Stdout, cmd.Stderr = dir, stdout, stderr
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			return results, client.ErrCancelled
+		}
+
+		result := hookResult{Command: hook}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+			results = append(results, result)
+			return results, fmt.Errorf("post-generation hook '%s' failed with exit code %d", hook, result.ExitCode)
+		} else if err != nil {
+			return results, fmt.Errorf("couldn't run post-generation hook '%s': %w", hook, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// openCommand returns the command opening the specified directory in the specified editor, or in the file explorer of the
+// specified platform if no editor is specified
+func openCommand(dir, editor, goos string) *exec.Cmd {
+	if len(editor) > 0 {
+		// the editor might be specified with arguments, e.g. "code --new-window"
+		args := append(strings.Fields(editor), dir)
+		return exec.Command(args[0], args[1:]...)
+	}
+
+	switch goos {
+	case "darwin":
+		return exec.Command("open", dir)
+	case "windows":
+		return exec.Command("explorer", dir)
+	default:
+		return exec.Command("xdg-open", dir)
+	}
+}
+
+// openDir opens the specified directory in the specified editor, waiting for it to exit since it might be a terminal editor, or
+// in the file explorer if no editor is specified
+func openDir(dir, editor string) error {
+	cmd := openCommand(dir, editor, runtime.GOOS)
+	if len(editor) == 0 {
+		return cmd.Start()
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// selectJavaVersion returns the specified Java version, displayed on the specified output, if it is supported according to the
+// specified configuration, failing in batch mode if it isn't, and lets the user select one otherwise. In batch mode, no version is
+// selected if none was specified so that the generator service uses its default one.
+func selectJavaVersion(out io.Writer, c *scaffold.Config, javaVersion string, batch bool) (string, error) {
+	versions := c.GetJavaVersions()
+	for _, v := range versions {
+		if v == javaVersion {
+			ui.OutputSelection(out, "Selected Java version", javaVersion)
+			return javaVersion, nil
+		}
+	}
+
+	message := "Java version"
+	if len(javaVersion) > 0 {
+		if batch {
+			return "", invalidf("unsupported Java version %s, supported ones are: %s", javaVersion, strings.Join(versions, ", "))
+		}
+		message = ui.ErrorMessage("Unsupported Java version", javaVersion)
+	} else if batch {
+		return "", nil
+	}
+	return ui.SelectInOrderE(message, versions, versions[0])
+}
+
+// getBOMs returns the BOMs of the specified configuration indexed by Spring Boot version, along with the default Spring Boot
+// version if any, failing if the configuration doesn't define any BOM since no project can be created then
+func getBOMs(c *scaffold.Config) (map[string]scaffold.Bom, string, error) {
+	boms, defaultVersion := c.GetBOMMap()
+	if len(boms) == 0 {
+		return nil, "", fmt.Errorf("generator service returned no Spring Boot versions")
+	}
+	return boms, defaultVersion, nil
+}
+
+// selectTemplates lets the user select the templates to create the project from amongst the specified template names, a single
+// one unless the generator service supports combining them according to the specified configuration
+func selectTemplates(c *scaffold.Config, message string, templateNames []string, canGoBack bool) (scaffold.TemplateNames, error) {
+	if !c.ComposableTemplates {
+		selectOne := ui.SelectE
+		if canGoBack {
+			selectOne = ui.SelectWithBackE
+		}
+		template, err := selectOne(message, templateNames)
+		if err != nil {
+			return nil, err
+		}
+		return scaffold.TemplateNames{template}, nil
+	}
+
+	multiSelect := ui.MultiSelectDescribedE
+	if canGoBack {
+		multiSelect = ui.MultiSelectDescribedWithBackE
+	}
+	return multiSelect(message, c.GetTemplateDescriptions(), nil)
+}
+
+// supportedFlagChanged checks whether the use of the supported Snowdrop BOM was explicitly requested or declined using either
+// --supported or --use-supported-bom
+func supportedFlagChanged(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("supported") || cmd.Flags().Changed("use-supported-bom")
+}
+
+// validateBOMVersion checks that the specified Snowdrop BOM version is one of the community or supported BOM versions associated
+// with the specified Spring Boot version
+func validateBOMVersion(c *scaffold.Config, springBootVersion, bomVersion string) error {
+	boms, _ := c.GetBOMMap()
+	valid := make([]string, 0, 2)
+	if bom, ok := boms[springBootVersion]; ok && len(bom.Snowdrop) > 0 {
+		valid = append(valid, bom.Snowdrop)
+	}
+	if supported := c.GetSupportedVersionFor(springBootVersion); len(supported) > 0 {
+		valid = append(valid, supported)
+	}
+
+	for _, v := range valid {
+		if v == bomVersion {
+			return nil
+		}
+	}
+	return invalidf("Snowdrop BOM version %s is not valid for Spring Boot %s, valid versions are: %s", bomVersion, springBootVersion, strings.Join(valid, ", "))
+}
+
+// sensitiveParameters returns the sorted keys of the specified parameters whose values are redacted since they likely hold secrets
+func sensitiveParameters(parameters map[string][]string) []string {
+	keys := []string{}
+	for key := range parameters {
+		if scaffold.IsSensitiveParameter(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseParameters parses the specified key=value parameters, values of repeated keys being accumulated
+func parseParameters(parameters []string) (map[string][]string, error) {
+	if len(parameters) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string][]string, len(parameters))
+	for _, parameter := range parameters {
+		kv := strings.SplitN(parameter, "=", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[0])) == 0 {
+			return nil, invalidf("invalid parameter '%s', parameters must be specified as key=value", parameter)
+		}
+		key := strings.TrimSpace(kv[0])
+		result[key] = append(result[key], kv[1])
+	}
+	return result, nil
+}
+
+// defaultPackageName computes the package name suggested for the specified project from its coordinates, sanitizing it to make
+// it a valid Java package name if needed
+func defaultPackageName(p *scaffold.Project) string {
+	packageName := p.GroupId + "." + p.ArtifactId
+	if sanitized := validation.SanitizePackageName(packageName); sanitized != packageName {
+		log.Warnf("%s is not a valid Java package name, using %s instead", packageName, sanitized)
+		return sanitized
+	}
+	return packageName
+}
+
+// mergeModules merges the specified lists of modules, trimming module names and dropping empty and duplicated ones
+func mergeModules(lists ...[]string) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, module := range list {
+			module = strings.TrimSpace(module)
+			if len(module) > 0 && !seen[module] {
+				seen[module] = true
+				result = append(result, module)
+			}
+		}
+	}
+	return result
+}
+
+// unknownElements returns the elements that are not contained in the specified sorted elements
+func unknownElements(elements, sortedElements []string) []string {
+	unknown := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if len(element) > 0 && !isContained(element, sortedElements) {
+			unknown = append(unknown, element)
+		}
+	}
+	return unknown
+}
+
 func isContained(element string, sortedElements []string) bool {
 	i := sort.SearchStrings(sortedElements, element)
 	if i < len(sortedElements) && sortedElements[i] == element {
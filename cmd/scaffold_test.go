@@ -0,0 +1,1359 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/client"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/scaffold"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/validation"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// zipEntry describes an entry of a zip archive, directories being identified by their trailing slash. Entries without a mode get
+// the default one of the zip package.
+type zipEntry struct {
+	name    string
+	mode    os.FileMode
+	content string
+}
+
+// zipContent returns a zip archive containing the specified entries, in order
+func zipContent(t *testing.T, entries []zipEntry) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.mode != 0 {
+			header.SetMode(e.mode)
+		}
+		entry, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = entry.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// createZip writes a zip archive containing the specified entries to test.zip in the specified directory, returning its path
+func createZip(t *testing.T, dir string, entries []zipEntry) string {
+	zipFile := filepath.Join(dir, "test.zip")
+	if err := ioutil.WriteFile(zipFile, zipContent(t, entries), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return zipFile
+}
+
+// readTree describes the tree rooted at the specified directory as zip entries, directory names ending with a slash
+func readTree(t *testing.T, root string) []zipEntry {
+	tree := []zipEntry{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		entry := zipEntry{name: filepath.ToSlash(rel), mode: info.Mode()}
+		if info.IsDir() {
+			entry.name += "/"
+		} else {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			entry.content = string(content)
+		}
+		tree = append(tree, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestUnzipRefusesPathTraversal(t *testing.T) {
+	tmp := t.TempDir()
+
+	zipFile := createZip(t, tmp, []zipEntry{{name: "../evil.txt", content: "evil"}})
+	dest := filepath.Join(tmp, "project")
+
+	err := Unzip(zipFile, dest)
+	if err == nil {
+		t.Fatal("Unzip should have refused to extract an entry outside of the destination directory")
+	}
+	if !strings.Contains(err.Error(), "illegal file path in archive") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmp, "evil.txt")); !os.IsNotExist(err) {
+		t.Error("file outside of the destination directory should not have been created")
+	}
+}
+
+func TestMissingRequiredFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  scaffold.Project
+		expected []string
+	}{
+		{
+			name:     "nothing provided",
+			project:  scaffold.Project{},
+			expected: []string{"springbootversion", "template or module", "groupid", "artifactid", "version"},
+		},
+		{
+			name:     "template",
+			project:  scaffold.Project{SpringBootVersion: "2.1.3", Template: scaffold.TemplateNames{"rest"}, GroupId: "me.snowdrop", ArtifactId: "demo", Version: "1.0"},
+			expected: []string{},
+		},
+		{
+			name:     "modules",
+			project:  scaffold.Project{SpringBootVersion: "2.1.3", Modules: []string{"core"}, GroupId: "me.snowdrop", Version: "1.0"},
+			expected: []string{"artifactid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing := missingRequiredFields(&tt.project)
+			if !reflect.DeepEqual(tt.expected, missing) {
+				t.Errorf("expected %v, got %v", tt.expected, missing)
+			}
+		})
+	}
+}
+
+func TestProjectDir(t *testing.T) {
+	tests := []struct {
+		outDir  string
+		wantErr bool
+	}{
+		{outDir: "myproject", wantErr: false},
+		{outDir: "nested/myproject", wantErr: false},
+		{outDir: "/tmp/myproject", wantErr: false},
+		{outDir: "/", wantErr: true},
+		{outDir: "", wantErr: true},
+		{outDir: ".", wantErr: true},
+		{outDir: "..", wantErr: true},
+		{outDir: "../myproject", wantErr: true},
+		{outDir: "nested/../../myproject", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outDir, func(t *testing.T) {
+			dir, err := projectDir("/tmp/current", tt.outDir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+			expected := filepath.Join("/tmp/current", tt.outDir)
+			if filepath.IsAbs(tt.outDir) {
+				expected = tt.outDir
+			}
+			if err == nil && dir != expected {
+				t.Errorf("unexpected project directory %s", dir)
+			}
+		})
+	}
+}
+
+func TestIsNonEmptyDir(t *testing.T) {
+	tmp := t.TempDir()
+
+	nonEmpty, err := isNonEmptyDir(filepath.Join(tmp, "missing"))
+	if err != nil || nonEmpty {
+		t.Errorf("missing directory should be considered empty, got %v, %v", nonEmpty, err)
+	}
+
+	nonEmpty, err = isNonEmptyDir(tmp)
+	if err != nil || nonEmpty {
+		t.Errorf("empty directory should be considered empty, got %v, %v", nonEmpty, err)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(tmp, "pom.xml"), []byte("<project/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nonEmpty, err = isNonEmptyDir(tmp)
+	if err != nil || !nonEmpty {
+		t.Errorf("directory with content should be considered non-empty, got %v, %v", nonEmpty, err)
+	}
+}
+
+func TestExtractProjectRemovesZipOnFailure(t *testing.T) {
+	tmp := t.TempDir()
+
+	dir := filepath.Join(tmp, "project")
+	err := extractProject(context.Background(), strings.NewReader("not a zip file"), dir, true, defaultExtractionLimits)
+	if err == nil {
+		t.Fatal("extracting a corrupt zip file should have failed")
+	}
+
+	if _, err := os.Stat(dir + ".zip"); !os.IsNotExist(err) {
+		t.Error("temporary zip file should have been removed")
+	}
+}
+
+func TestExtractProjectKeepArchive(t *testing.T) {
+	tmp := t.TempDir()
+	content := zipContent(t, []zipEntry{{name: "pom.xml"}})
+
+	for _, keep := range []bool{false, true} {
+		dir := filepath.Join(tmp, fmt.Sprintf("project-%v", keep))
+		if err := extractProject(context.Background(), bytes.NewReader(content), dir, keep, defaultExtractionLimits); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "pom.xml")); err != nil {
+			t.Errorf("project should have been extracted: %v", err)
+		}
+		if _, err := os.Stat(dir + ".zip"); (err == nil) != keep {
+			t.Errorf("archive kept = %v, expected %v", err == nil, keep)
+		}
+	}
+}
+
+func TestUnknownElements(t *testing.T) {
+	known := []string{"core", "jpa", "web"}
+
+	unknown := unknownElements([]string{"core", "foo", "web", "", "bar"}, known)
+	if !reflect.DeepEqual([]string{"foo", "bar"}, unknown) {
+		t.Errorf("expected [foo bar], got %v", unknown)
+	}
+
+	unknown = unknownElements([]string{"core", "jpa"}, known)
+	if len(unknown) != 0 {
+		t.Errorf("expected no unknown element, got %v", unknown)
+	}
+}
+
+func TestUnzipCreatesEmptyDirectories(t *testing.T) {
+	tmp := t.TempDir()
+
+	zipFile := createZip(t, tmp, []zipEntry{{name: "src/main/resources/", mode: os.ModeDir | 0750}, {name: "pom.xml"}})
+	dest := filepath.Join(tmp, "project")
+	if err := Unzip(zipFile, dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "src", "main", "resources"))
+	if err != nil {
+		t.Fatalf("empty directory should have been created: %v", err)
+	}
+	if !info.IsDir() {
+		t.Error("empty directory entry should have been created as a directory")
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("expected directory permissions 0750, got %v", info.Mode().Perm())
+	}
+}
+
+func TestDirMode(t *testing.T) {
+	if mode := dirMode(os.ModeDir | 0700); mode != 0700 {
+		t.Errorf("expected 0700, got %v", mode)
+	}
+	if mode := dirMode(os.ModeDir); mode != defaultDirMode {
+		t.Errorf("directory without permissions should use default mode, got %v", mode)
+	}
+}
+
+func TestValidateBOMVersion(t *testing.T) {
+	c := &scaffold.Config{Boms: []scaffold.Bom{
+		{Community: "2.1.3.RELEASE", Snowdrop: "2.1.3-1", Supported: "2.1.3-1-redhat-00001"},
+		{Community: "2.1.4.RELEASE", Snowdrop: "2.1.4-1"},
+	}}
+
+	tests := []struct {
+		name       string
+		sbVersion  string
+		bomVersion string
+		wantErr    bool
+	}{
+		{name: "community", sbVersion: "2.1.3.RELEASE", bomVersion: "2.1.3-1", wantErr: false},
+		{name: "supported", sbVersion: "2.1.3.RELEASE", bomVersion: "2.1.3-1-redhat-00001", wantErr: false},
+		{name: "other Spring Boot version", sbVersion: "2.1.3.RELEASE", bomVersion: "2.1.4-1", wantErr: true},
+		{name: "typo", sbVersion: "2.1.4.RELEASE", bomVersion: "2.1.4-2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBOMVersion(c, tt.sbVersion, tt.bomVersion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInitGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "scaffold")
+	t.Setenv("GIT_AUTHOR_EMAIL", "scaffold@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "scaffold")
+	t.Setenv("GIT_COMMITTER_EMAIL", "scaffold@example.com")
+
+	tmp := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(tmp, "pom.xml"), []byte("<project/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := initGitRepository(tmp, "https://github.com/snowdrop/demo.git"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", tmp, "ls-files").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "pom.xml" {
+		t.Errorf("generated files should have been committed, got %s", out)
+	}
+	out, err = exec.Command("git", "-C", tmp, "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "https://github.com/snowdrop/demo.git" {
+		t.Errorf("unexpected origin %s", out)
+	}
+}
+
+func TestDefaultServiceEndpoint(t *testing.T) {
+	t.Setenv(serviceURLEnvVar, "")
+	if url := defaultServiceEndpoint(); url != ServiceEndpoint {
+		t.Errorf("expected compiled-in endpoint %s, got %s", ServiceEndpoint, url)
+	}
+
+	t.Setenv(serviceURLEnvVar, "https://generator.example.com")
+	if url := defaultServiceEndpoint(); url != "https://generator.example.com" {
+		t.Errorf("expected endpoint from environment, got %s", url)
+	}
+
+	t.Setenv(serviceURLEnvVar, "https://generator.example.com/")
+	if url := defaultServiceEndpoint(); url != "https://generator.example.com" {
+		t.Errorf("expected trailing slash to be removed, got %s", url)
+	}
+}
+
+func TestIsPublicServiceEndpoint(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://generator.snowdrop.me", want: true},
+		{url: "https://generator.snowdrop.me/", want: true},
+		{url: "https://generator.example.com", want: false},
+		{url: "http://localhost:8080", want: false},
+	}
+	for _, tt := range tests {
+		if got := isPublicServiceEndpoint(tt.url); got != tt.want {
+			t.Errorf("isPublicServiceEndpoint(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	p := &scaffold.Project{
+		GroupId:            "me.snowdrop",
+		ArtifactId:         "demo",
+		Version:            "1.0.0-SNAPSHOT",
+		PackageName:        "me.snowdrop.demo",
+		BuildTool:          "maven",
+		Packaging:          "war",
+		SpringBootVersion:  "2.1.3.RELEASE",
+		SnowdropBomVersion: "2.1.3-1",
+		Modules:            []string{"core", "web"},
+	}
+
+	var out bytes.Buffer
+	printSummary(&out, p, "/tmp/demo")
+
+	for _, expected := range []string{"2.1.3.RELEASE", "2.1.3-1", "core, web", "me.snowdrop:demo:1.0.0-SNAPSHOT", "war", "/tmp/demo"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("summary should contain %s, got:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Template") {
+		t.Errorf("summary shouldn't mention a template when using modules, got:\n%s", out.String())
+	}
+}
+
+func TestSaveArchive(t *testing.T) {
+	tmp := t.TempDir()
+
+	path := filepath.Join(tmp, "demo.zip")
+	if err := saveArchive(strings.NewReader("zip content"), path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != "zip content" {
+		t.Errorf("archive should have been saved as is, got %s (%v)", content, err)
+	}
+
+	failing := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+	if err = saveArchive(failing, path); err == nil {
+		t.Fatal("saving the archive should fail if the content can't be read")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("partially written archive should have been removed")
+	}
+}
+
+func TestModuleFetcher(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("- name: web\n- name: core\n"))
+	}))
+	defer server.Close()
+
+	generator, err := client.New(&scaffold.Project{UrlService: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := newModuleFetcher(context.Background(), generator)
+	fetcher.prefetch("2.1.3.RELEASE")
+
+	names, err := fetcher.moduleNames("2.1.3.RELEASE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"core", "web"}, names) {
+		t.Errorf("expected [core web], got %v", names)
+	}
+	if _, err = fetcher.modules("2.1.3.RELEASE"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := atomic.LoadInt32(&requests); count != 1 {
+		t.Errorf("modules should only be retrieved once per version, got %d requests", count)
+	}
+
+	if _, err = fetcher.modules("2.1.4.RELEASE"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := atomic.LoadInt32(&requests); count != 2 {
+		t.Errorf("modules should be retrieved for another version, got %d requests", count)
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	info := versionInfo()
+	for _, expected := range []string{version, commit, date} {
+		if !strings.Contains(info, expected) {
+			t.Errorf("version information should contain %s, got %s", expected, info)
+		}
+	}
+}
+
+func TestMergeModules(t *testing.T) {
+	tests := []struct {
+		name     string
+		module   []string
+		modules  []string
+		expected []string
+	}{
+		{name: "none", expected: []string{}},
+		{name: "repeated flag only", module: []string{"web", "jpa"}, expected: []string{"web", "jpa"}},
+		{name: "list only", modules: []string{"web", " actuator", "", "jpa "}, expected: []string{"web", "actuator", "jpa"}},
+		{name: "both", module: []string{"web"}, modules: []string{"actuator", "web"}, expected: []string{"web", "actuator"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if merged := mergeModules(tt.module, tt.modules); !reflect.DeepEqual(tt.expected, merged) {
+				t.Errorf("expected %v, got %v", tt.expected, merged)
+			}
+		})
+	}
+}
+
+func TestParseParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters []string
+		expected   map[string][]string
+		wantErr    bool
+	}{
+		{name: "none"},
+		{name: "valid", parameters: []string{"javaversion=11", "packaging=war", "javaversion=8"}, expected: map[string][]string{"javaversion": {"11", "8"}, "packaging": {"war"}}},
+		{name: "empty value", parameters: []string{"description="}, expected: map[string][]string{"description": {""}}},
+		{name: "value with equal sign", parameters: []string{"description=a=b"}, expected: map[string][]string{"description": {"a=b"}}},
+		{name: "missing value", parameters: []string{"javaversion"}, wantErr: true},
+		{name: "missing key", parameters: []string{"=11"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseParameters(tt.parameters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(tt.expected, parsed) {
+				t.Errorf("expected %v, got %v", tt.expected, parsed)
+			}
+		})
+	}
+}
+
+func TestOpenCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		editor   string
+		goos     string
+		expected []string
+	}{
+		{name: "linux", goos: "linux", expected: []string{"xdg-open", "/tmp/demo"}},
+		{name: "macOS", goos: "darwin", expected: []string{"open", "/tmp/demo"}},
+		{name: "windows", goos: "windows", expected: []string{"explorer", "/tmp/demo"}},
+		{name: "editor", editor: "vim", goos: "linux", expected: []string{"vim", "/tmp/demo"}},
+		{name: "editor with arguments", editor: "code --new-window", goos: "darwin", expected: []string{"code", "--new-window", "/tmp/demo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := openCommand("/tmp/demo", tt.editor, tt.goos)
+			if !reflect.DeepEqual(tt.expected, cmd.Args) {
+				t.Errorf("expected %v, got %v", tt.expected, cmd.Args)
+			}
+		})
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	if args := shellCommand(context.Background(), "./mvnw compile", "linux").Args; !reflect.DeepEqual(args, []string{"sh", "-c", "./mvnw compile"}) {
+		t.Errorf("unexpected command %v", args)
+	}
+	if args := shellCommand(context.Background(), "mvnw.cmd compile", "windows").Args; !reflect.DeepEqual(args, []string{"cmd", "/C", "mvnw.cmd compile"}) {
+		t.Errorf("unexpected command %v", args)
+	}
+}
+
+func TestRunPostHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run using sh")
+	}
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	results, err := runPostHooks(context.Background(), dir, []string{"pwd", "echo oops >&2; exit 3", "touch never"}, &stdout, &stderr)
+	if err == nil || err.Error() != "post-generation hook 'echo oops >&2; exit 3' failed with exit code 3" {
+		t.Errorf("expected failing hook to be reported, got %v", err)
+	}
+	expected := []hookResult{{Command: "pwd"}, {Command: "echo oops >&2; exit 3", ExitCode: 3}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+	if resolved, _ := filepath.EvalSymlinks(dir); strings.TrimSpace(stdout.String()) != resolved && strings.TrimSpace(stdout.String()) != dir {
+		t.Errorf("expected hook to run in %s, got %s", dir, stdout.String())
+	}
+	if stderr.String() != "oops\n" {
+		t.Errorf("expected hook error output to be streamed, got %q", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "never")); !os.IsNotExist(err) {
+		t.Errorf("hooks following a failing one shouldn't run")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := runPostHooks(ctx, dir, []string{"true"}, &stdout, &stderr); err != client.ErrCancelled {
+		t.Errorf("expected cancellation to be reported, got %v", err)
+	}
+}
+
+func TestUnzip(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []zipEntry
+		expected []zipEntry
+	}{
+		{
+			name:     "empty archive",
+			entries:  []zipEntry{},
+			expected: []zipEntry{},
+		},
+		{
+			name: "flat files",
+			entries: []zipEntry{
+				{name: "README.md", mode: 0644, content: "readme"},
+				{name: "pom.xml", mode: 0644, content: "<project/>"},
+			},
+			expected: []zipEntry{
+				{name: "README.md", mode: 0644, content: "readme"},
+				{name: "pom.xml", mode: 0644, content: "<project/>"},
+			},
+		},
+		{
+			name: "nested directories with explicit entries",
+			entries: []zipEntry{
+				{name: "src/", mode: os.ModeDir | 0755},
+				{name: "src/main/", mode: os.ModeDir | 0700},
+				{name: "src/main/java/", mode: os.ModeDir | 0755},
+				{name: "src/main/java/App.java", mode: 0644, content: "class App {}"},
+			},
+			expected: []zipEntry{
+				{name: "src/", mode: os.ModeDir | 0755},
+				{name: "src/main/", mode: os.ModeDir | 0700},
+				{name: "src/main/java/", mode: os.ModeDir | 0755},
+				{name: "src/main/java/App.java", mode: 0644, content: "class App {}"},
+			},
+		},
+		{
+			name: "nested directories implied by file entries",
+			entries: []zipEntry{
+				{name: "src/main/resources/application.properties", mode: 0644, content: "server.port=8080"},
+			},
+			expected: []zipEntry{
+				{name: "src/", mode: os.ModeDir | defaultDirMode},
+				{name: "src/main/", mode: os.ModeDir | defaultDirMode},
+				{name: "src/main/resources/", mode: os.ModeDir | defaultDirMode},
+				{name: "src/main/resources/application.properties", mode: 0644, content: "server.port=8080"},
+			},
+		},
+		{
+			name: "file modes",
+			entries: []zipEntry{
+				{name: "mvnw", mode: 0755, content: "#!/bin/sh"},
+				{name: "mvnw.cmd", mode: 0644, content: "@echo off"},
+				{name: "secret.txt", mode: 0600, content: "secret"},
+			},
+			expected: []zipEntry{
+				{name: "mvnw", mode: 0755, content: "#!/bin/sh"},
+				{name: "mvnw.cmd", mode: 0644, content: "@echo off"},
+				{name: "secret.txt", mode: 0600, content: "secret"},
+			},
+		},
+		{
+			name: "directory without usable permissions",
+			entries: []zipEntry{
+				{name: "empty/", mode: os.ModeDir},
+			},
+			expected: []zipEntry{
+				{name: "empty/", mode: os.ModeDir | defaultDirMode},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+
+			zipFile := createZip(t, tmp, tt.entries)
+
+			dest := filepath.Join(tmp, "project")
+			if err := Unzip(zipFile, dest); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// an empty archive doesn't create anything, not even the destination directory
+			if len(tt.entries) == 0 {
+				if _, err := os.Stat(dest); !os.IsNotExist(err) {
+					t.Errorf("empty archive should not have created %s", dest)
+				}
+				return
+			}
+
+			if tree := readTree(t, dest); !reflect.DeepEqual(tree, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, tree)
+			}
+		})
+	}
+}
+
+func TestFilterModulesByCategory(t *testing.T) {
+	modules := []scaffold.Module{
+		{Name: "web", Tags: []string{"web"}},
+		{Name: "jpa", Tags: []string{"data"}},
+		{Name: "core"},
+	}
+
+	if filtered, err := filterModulesByCategory(modules, ""); err != nil || len(filtered) != len(modules) {
+		t.Errorf("all modules should be kept without category, got %v (%v)", filtered, err)
+	}
+	if filtered, err := filterModulesByCategory(modules, "data"); err != nil || len(filtered) != 1 || filtered[0].Name != "jpa" {
+		t.Errorf("expected only jpa module, got %v (%v)", filtered, err)
+	}
+	_, err := filterModulesByCategory(modules, "messaging")
+	if err == nil || !strings.Contains(err.Error(), "available categories: data, web") {
+		t.Errorf("expected unknown category to be reported with the available ones, got %v", err)
+	}
+}
+
+func TestApplySpec(t *testing.T) {
+	p := &scaffold.Project{}
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVarP(&p.GroupId, "groupid", "g", "", "")
+	cmd.Flags().StringVarP(&p.ArtifactId, "artifactid", "i", "", "")
+	cmd.Flags().StringSliceVarP((*[]string)(&p.Template), "template", "t", nil, "")
+	cmd.Flags().StringSliceVarP(&p.Modules, "module", "m", []string{}, "")
+	cmd.Flags().BoolVarP(&p.UseAp4k, "ap4k", "a", false, "")
+	if err := cmd.ParseFlags([]string{"--artifactid", "overridden", "--module", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &scaffold.Project{GroupId: "me.snowdrop", ArtifactId: "demo", Template: scaffold.TemplateNames{"rest"}, UseAp4k: true}
+	applySpec(cmd, p, spec)
+
+	expected := &scaffold.Project{GroupId: "me.snowdrop", ArtifactId: "overridden", Modules: []string{"web"}, UseAp4k: true}
+	if !reflect.DeepEqual(expected, p) {
+		t.Errorf("expected flags to override the spec, got %+v", p)
+	}
+
+	cmd.Flags().BoolVar(&p.UseSupported, "use-supported-bom", false, "")
+	if err := cmd.ParseFlags([]string{"--use-supported-bom=false"}); err != nil {
+		t.Fatal(err)
+	}
+	applySpec(cmd, p, &scaffold.Project{UseSupported: true})
+	if p.UseSupported {
+		t.Errorf("expected --use-supported-bom to override the spec")
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	c := &scaffold.Config{Modules: []scaffold.Module{
+		{Name: "web", Conflicts: []string{"webflux"}},
+		{Name: "undertow", Conflicts: []string{"jetty"}},
+	}}
+	keepFirst := func(message string, options []string, defaultValue ...string) (string, error) {
+		return options[0], nil
+	}
+
+	modules, err := resolveConflicts(c, []string{"web", "jpa"}, true, keepFirst)
+	if err != nil || !reflect.DeepEqual([]string{"web", "jpa"}, modules) {
+		t.Errorf("modules without conflict should be kept, got %v (%v)", modules, err)
+	}
+
+	_, err = resolveConflicts(c, []string{"web", "webflux", "jetty", "undertow"}, true, keepFirst)
+	if err == nil || !strings.Contains(err.Error(), "web and webflux, jetty and undertow") {
+		t.Errorf("expected conflicts to be reported in batch mode, got %v", err)
+	}
+
+	modules, err = resolveConflicts(c, []string{"webflux", "jpa", "web", "jetty", "undertow"}, false, keepFirst)
+	if err != nil || !reflect.DeepEqual([]string{"webflux", "jpa", "jetty"}, modules) {
+		t.Errorf("expected conflicts to be resolved by the user, got %v (%v)", modules, err)
+	}
+}
+
+func TestGetBOMs(t *testing.T) {
+	empty := &scaffold.Config{}
+	if err := yaml.Unmarshal([]byte("templates: []\nbomversions: []\nmodules: []\n"), empty); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := getBOMs(empty)
+	if err == nil || err.Error() != "generator service returned no Spring Boot versions" {
+		t.Errorf("expected missing Spring Boot versions to be reported, got %v", err)
+	}
+
+	c := &scaffold.Config{Boms: []scaffold.Bom{{Community: "2.1.3.RELEASE", Snowdrop: "2.1.3-1", Default: true}}}
+	boms, defaultVersion, err := getBOMs(c)
+	if err != nil || len(boms) != 1 || defaultVersion != "2.1.3.RELEASE" {
+		t.Errorf("unexpected BOMs %v and default version %s (%v)", boms, defaultVersion, err)
+	}
+}
+
+func TestResolveSpringBootVersion(t *testing.T) {
+	boms := map[string]scaffold.Bom{
+		"2.1.3.RELEASE": {Community: "2.1.3.RELEASE"},
+		"2.7.18":        {Community: "2.7.18"},
+		"3.2.1":         {Community: "3.2.1"},
+		"3.2.10":        {Community: "3.2.10"},
+		"1.4.RELEASE":   {},
+		"1.4.2":         {Community: "1.4.2"},
+	}
+	tests := []struct {
+		name      string
+		version   string
+		expected  string
+		ambiguous bool
+	}{
+		{name: "exact", version: "3.2.1", expected: "3.2.1"},
+		{name: "without release suffix", version: "2.1.3", expected: "2.1.3.RELEASE"},
+		{name: "without release suffix, missing community version", version: "1.4", expected: "1.4.RELEASE"},
+		{name: "unique prefix", version: "2.7", expected: "2.7.18"},
+		{name: "ambiguous prefix", version: "3.2", ambiguous: true},
+		{name: "prefix only matches whole segments", version: "2.7.1", expected: "2.7.1.RELEASE"},
+		{name: "unknown", version: "1.5", expected: "1.5.RELEASE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := resolveSpringBootVersion(tt.version, boms)
+			if tt.ambiguous {
+				if err == nil || exitCode(err) != 2 || !strings.Contains(err.Error(), "use one of: 3.2.1, 3.2.10") {
+					t.Errorf("expected ambiguous version to be reported, got %s (%v)", version, err)
+				}
+				return
+			}
+			if err != nil || version != tt.expected {
+				t.Errorf("expected %s, got %s (%v)", tt.expected, version, err)
+			}
+		})
+	}
+}
+
+func TestSelectJavaVersion(t *testing.T) {
+	c := &scaffold.Config{JavaVersions: []string{"11", "17"}}
+
+	if v, err := selectJavaVersion(ioutil.Discard, c, "17", true); err != nil || v != "17" {
+		t.Errorf("supported version should be kept, got %s (%v)", v, err)
+	}
+	if v, err := selectJavaVersion(ioutil.Discard, c, "", true); err != nil || len(v) > 0 {
+		t.Errorf("no version should be selected in batch mode, got %s (%v)", v, err)
+	}
+	_, err := selectJavaVersion(ioutil.Discard, c, "8", true)
+	if err == nil || !strings.Contains(err.Error(), "supported ones are: 11, 17") {
+		t.Errorf("expected unsupported version to be reported, got %v", err)
+	}
+	if v, err := selectJavaVersion(ioutil.Discard, &scaffold.Config{}, "21", true); err != nil || v != "21" {
+		t.Errorf("commonly supported version should be accepted when the service doesn't list any, got %s (%v)", v, err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	_, pathErr := os.Open("/does/not/exist")
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "generic", err: errors.New("boom"), expected: exitFailure},
+		{name: "invalid input", err: invalidf("--editor requires --open"), expected: exitInvalid},
+		{name: "wrapped invalid input", err: fmt.Errorf("spec: %w", invalidInput(errors.New("bad"))), expected: exitInvalid},
+		{name: "bad request", err: &client.BadRequestError{Err: errors.New("rejected")}, expected: exitInvalid},
+		{name: "not found", err: &client.NotFoundError{Err: errors.New("missing")}, expected: exitInvalid},
+		{name: "network", err: fmt.Errorf("couldn't retrieve configuration: %w", &client.ServiceUnavailableError{Err: errors.New("down")}), expected: exitNetwork},
+		{name: "filesystem", err: fmt.Errorf("failed to download file due to %w", pathErr), expected: exitFilesystem},
+		{name: "cancelled", err: client.ErrCancelled, expected: exitCancelled},
+		{name: "interrupted prompt", err: terminal.InterruptErr, expected: exitCancelled},
+		{name: "wrapped interrupted prompt", err: fmt.Errorf("couldn't select modules: %w", terminal.InterruptErr), expected: exitCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.err); code != tt.expected {
+				t.Errorf("expected exit code %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestVerifyArchive(t *testing.T) {
+	content := zipContent(t, []zipEntry{{name: "pom.xml"}, {name: "src/main/java/App.java"}})
+
+	entries, err := verifyArchive(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"pom.xml", "src/main/java/App.java"}, entries) {
+		t.Errorf("unexpected entries %v", entries)
+	}
+
+	if _, err = verifyArchive(strings.NewReader("<html>not a zip</html>")); err == nil || !strings.Contains(err.Error(), "not valid") {
+		t.Errorf("expected invalid archive to be reported, got %v", err)
+	}
+}
+
+func TestReviewProject(t *testing.T) {
+	p := &scaffold.Project{GroupId: "me.snowdrop", ArtifactId: "demo", Version: "1,0", PackageName: "me.snowdrop.demo"}
+	choices := []string{"Edit Version", createProjectChoice}
+	choose := func(message string, options []string, defaultValue ...string) (string, error) {
+		choice := choices[0]
+		choices = choices[1:]
+		return choice, nil
+	}
+	var asked string
+	ask := func(message, provided string, validator validation.Validator, defaultValue ...string) (string, error) {
+		asked = message
+		if len(defaultValue) != 1 || defaultValue[0] != "1,0" {
+			t.Errorf("expected current value to be suggested, got %v", defaultValue)
+		}
+		return "1.0", nil
+	}
+
+	outDir := "demo"
+	locate := func() (string, error) { return "/tmp/" + outDir, nil }
+	var out bytes.Buffer
+	confirmed, err := reviewProject(&out, p, "/tmp/demo", &outDir, locate, choose, ask)
+	if err != nil || !confirmed {
+		t.Fatalf("expected project to be confirmed, got %v (%v)", confirmed, err)
+	}
+	if asked != "Version" || p.Version != "1.0" {
+		t.Errorf("expected version to be edited, asked %s and got %s", asked, p.Version)
+	}
+	if !strings.Contains(out.String(), "me.snowdrop:demo:1.0") {
+		t.Errorf("expected summary to be displayed again after editing, got %s", out.String())
+	}
+
+	choices = []string{abortChoice}
+	if confirmed, err = reviewProject(&out, p, "/tmp/demo", &outDir, locate, choose, ask); err != nil || confirmed {
+		t.Errorf("expected project creation to be aborted, got %v (%v)", confirmed, err)
+	}
+}
+
+func TestReviewProjectRelocates(t *testing.T) {
+	currentDir := t.TempDir()
+	tests := []struct {
+		name     string
+		outDir   string
+		field    string
+		value    string
+		expected string
+	}{
+		{name: "default location follows artifact id", outDir: "{artifactId}", field: "Artifact Id", value: "other", expected: "other"},
+		{name: "templated location follows artifact id", outDir: "projects/{artifactId}", field: "Artifact Id", value: "other", expected: "projects/other"},
+		{name: "edited location", outDir: "{artifactId}", field: "Project location", value: "elsewhere/{artifactId}", expected: "elsewhere/demo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &scaffold.Project{GroupId: "me.snowdrop", ArtifactId: "demo", Version: "1.0", PackageName: "me.snowdrop.demo"}
+			outDir := tt.outDir
+			locations := 0
+			locate := func() (string, error) {
+				locations++
+				var err error
+				if p.OutDir, err = expandOutDir(outDir, p); err != nil {
+					return "", err
+				}
+				return projectDir(currentDir, p.OutDir)
+			}
+			location, err := locate()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			choices := []string{"Edit " + tt.field, createProjectChoice}
+			choose := func(message string, options []string, defaultValue ...string) (string, error) {
+				choice := choices[0]
+				choices = choices[1:]
+				return choice, nil
+			}
+			ask := func(message, provided string, validator validation.Validator, defaultValue ...string) (string, error) {
+				return tt.value, validator(tt.value)
+			}
+			var out bytes.Buffer
+			if confirmed, err := reviewProject(&out, p, location, &outDir, locate, choose, ask); err != nil || !confirmed {
+				t.Fatalf("expected project to be confirmed, got %v (%v)", confirmed, err)
+			}
+			if locations != 2 {
+				t.Errorf("expected location to be computed again after editing, computed %d times", locations)
+			}
+			if p.OutDir != tt.expected {
+				t.Errorf("expected project location %s, got %s", tt.expected, p.OutDir)
+			}
+			if expected := filepath.Join(currentDir, tt.expected); !strings.HasSuffix(out.String(), expected+"\n") {
+				t.Errorf("expected summary to show location %s, got:\n%s", expected, out.String())
+			}
+		})
+	}
+}
+
+func TestBaseDir(t *testing.T) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir, err := baseDir(""); err != nil || dir != currentDir {
+		t.Errorf("expected current directory %s by default, got %s (%v)", currentDir, dir, err)
+	}
+
+	tmp := t.TempDir()
+	if dir, err := baseDir(tmp); err != nil || dir != tmp {
+		t.Errorf("expected work directory %s, got %s (%v)", tmp, dir, err)
+	}
+	if entries, _ := ioutil.ReadDir(tmp); len(entries) > 0 {
+		t.Errorf("checking the work directory shouldn't leave any file behind, found %s", entries[0].Name())
+	}
+
+	file := filepath.Join(tmp, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, invalid := range []string{file, filepath.Join(tmp, "missing")} {
+		if _, err := baseDir(invalid); exitCode(err) != exitInvalid {
+			t.Errorf("expected %s to be reported as an invalid work directory, got %v", invalid, err)
+		}
+	}
+}
+
+func TestUnzipCancelled(t *testing.T) {
+	tmp := t.TempDir()
+	zipFile := createZip(t, tmp, []zipEntry{{name: "pom.xml"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dest := filepath.Join(tmp, "project")
+	if err := unzip(ctx, zipFile, dest, defaultExtractionLimits); err != client.ErrCancelled {
+		t.Errorf("expected extraction to be cancelled, got %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("nothing should have been extracted once cancelled")
+	}
+}
+
+func TestCleanupPartialProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		created bool
+		keep    bool
+		removed bool
+	}{
+		{name: "created", created: true, removed: true},
+		{name: "kept", created: true, keep: true, removed: false},
+		{name: "pre-existing", created: false, removed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "project")
+			if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			cleanupPartialProject(dir, tt.created, tt.keep)
+			if _, err := os.Stat(dir); os.IsNotExist(err) != tt.removed {
+				t.Errorf("expected removed = %v, got %v", tt.removed, err)
+			}
+		})
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		config   string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "healthy",
+			status:   http.StatusOK,
+			config:   "templates:\n- name: rest\nbomversions:\n- community: 2.1.3.RELEASE\n  snowdrop: 2.1.3-1\n  default: true\n",
+			expected: []string{"[PASS] DNS resolution of 127.0.0.1: IP address", "[PASS] Generator service configuration: 1 templates, 1 Spring Boot versions", "[PASS] Generator service API version: not reported"},
+		},
+		{
+			name:     "incompatible API version",
+			status:   http.StatusOK,
+			config:   "apiversion: \"2.1\"\ntemplates: []\nbomversions:\n- community: 2.1.3.RELEASE\n  snowdrop: 2.1.3-1\n  default: true\n",
+			expected: []string{"[PASS] Generator service configuration", "[WARN] Generator service API version: generator service API version 2.1 is not compatible"},
+		},
+		{
+			name:     "invalid configuration",
+			status:   http.StatusOK,
+			config:   "bomversions: not a list\n",
+			expected: []string{"[PASS] Reachability", "[FAIL] Generator service configuration"},
+			wantErr:  true,
+		},
+		{
+			name:     "unavailable",
+			status:   http.StatusServiceUnavailable,
+			config:   "down",
+			expected: []string{"[FAIL] Reachability", "[FAIL] Generator service configuration: skipped"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "1")
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.config))
+			}))
+			defer server.Close()
+
+			p := &scaffold.Project{UrlService: server.URL}
+			generator, err := client.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			err = printDiagnoses(&out, diagnose(context.Background(), p, generator))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExpandOutDir(t *testing.T) {
+	p := &scaffold.Project{GroupId: "me.snowdrop", ArtifactId: "demo", Version: "1.0.0-SNAPSHOT"}
+	tests := []struct {
+		outDir   string
+		expected string
+		wantErr  bool
+	}{
+		{outDir: "demo", expected: "demo"},
+		{outDir: "projects/{artifactId}", expected: "projects/demo"},
+		{outDir: "{artifactid}-{version}", expected: "demo-1.0.0-SNAPSHOT"},
+		{outDir: "/tmp/{groupId}/{artifactId}", expected: "/tmp/me.snowdrop/demo"},
+		{outDir: "projects/{unknown}", wantErr: true},
+		{outDir: "projects/{artifactId", wantErr: true},
+		{outDir: "projects/{packageName}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outDir, func(t *testing.T) {
+			expanded, err := expandOutDir(tt.outDir, p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+			if expanded != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, expanded)
+			}
+		})
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected byteSize
+		display  string
+		wantErr  bool
+	}{
+		{value: "100MB", expected: 100 << 20, display: "100MB"},
+		{value: "512 kb", expected: 512 << 10, display: "512KB"},
+		{value: "2GB", expected: 2 << 30, display: "2GB"},
+		{value: "1500", expected: 1500, display: "1500B"},
+		{value: "0", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "lots", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var size byteSize
+			err := size.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+			if err == nil && (size != tt.expected || size.String() != tt.display) {
+				t.Errorf("expected %d displayed as %s, got %d displayed as %s", tt.expected, tt.display, size, size.String())
+			}
+		})
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	limitErr := errors.New("too large")
+	read, err := ioutil.ReadAll(&limitedReader{reader: strings.NewReader("12345"), remaining: 5, err: limitErr})
+	if err != nil || string(read) != "12345" {
+		t.Errorf("content within the limit should be read, got %s (%v)", read, err)
+	}
+
+	_, err = ioutil.ReadAll(&limitedReader{reader: strings.NewReader("123456"), remaining: 5, err: limitErr})
+	if err != limitErr {
+		t.Errorf("expected content exceeding the limit to be reported, got %v", err)
+	}
+}
+
+func TestUnzipDecompressionBomb(t *testing.T) {
+	// zeros compress extremely well, a few KB expanding to several MB
+	zeros := strings.Repeat("\x00", 4<<20)
+	tests := []struct {
+		name     string
+		entries  []zipEntry
+		limits   extractionLimits
+		expected string
+	}{
+		{
+			name:    "within limits",
+			entries: []zipEntry{{name: "a", content: zeros}, {name: "b", content: zeros}},
+			limits:  extractionLimits{file: 4 << 20, total: 8 << 20},
+		},
+		{
+			name:     "file too large",
+			entries:  []zipEntry{{name: "bomb", content: zeros}},
+			limits:   extractionLimits{file: 1 << 20, total: 8 << 20},
+			expected: "archive entry bomb exceeds the maximum extracted file size of 1MB",
+		},
+		{
+			name:     "total too large",
+			entries:  []zipEntry{{name: "a", content: zeros}, {name: "b", content: zeros}},
+			limits:   extractionLimits{file: 4 << 20, total: 6 << 20},
+			expected: "extracting archive entry b exceeds the maximum total extracted size of 6MB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			zipFile := createZip(t, tmp, tt.entries)
+			if info, err := os.Stat(zipFile); err != nil || info.Size() > 64<<10 {
+				t.Fatalf("expected a highly compressed archive, got %v (%v)", info.Size(), err)
+			}
+
+			err := unzip(context.Background(), zipFile, filepath.Join(tmp, "project"), tt.limits)
+			if len(tt.expected) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tt.expected) > 0 && (err == nil || err.Error() != tt.expected) {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadGeneratorServiceConfigValidatesCache(t *testing.T) {
+	valid := "templates:\n- name: rest\nbomversions:\n- community: 2.1.3.RELEASE\n  snowdrop: 2.1.3-1\n  default: true\n"
+	tests := []struct {
+		name      string
+		offline   bool
+		cached    *scaffold.Config
+		expected  string
+		wantErr   string
+		wantFetch bool
+	}{
+		{
+			name:     "valid cache",
+			cached:   &scaffold.Config{Templates: []scaffold.Template{{Name: "cached"}}, Boms: []scaffold.Bom{{Community: "2.1.3.RELEASE", Snowdrop: "2.1.3-1", Default: true}}},
+			expected: "cached",
+		},
+		{
+			name:      "invalid cache is refreshed",
+			cached:    &scaffold.Config{Templates: []scaffold.Template{{Name: "cached"}}},
+			expected:  "rest",
+			wantFetch: true,
+		},
+		{
+			name:     "valid cache offline",
+			offline:  true,
+			cached:   &scaffold.Config{Templates: []scaffold.Template{{Name: "cached"}}, Boms: []scaffold.Bom{{Community: "2.1.3.RELEASE", Snowdrop: "2.1.3-1", Default: true}}},
+			expected: "cached",
+		},
+		{
+			name:    "invalid cache offline",
+			offline: true,
+			cached:  &scaffold.Config{Templates: []scaffold.Template{{Name: "cached"}}},
+			wantErr: "is invalid, run once without --offline to refresh it: generator service configuration is invalid: no Spring Boot versions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("HOME", dir)
+			t.Setenv("XDG_CACHE_HOME", dir)
+			fetched := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetched = true
+				w.Write([]byte(valid))
+			}))
+			defer server.Close()
+
+			p := &scaffold.Project{UrlService: server.URL, Offline: tt.offline, ConfigTTL: time.Hour}
+			generator, err := client.New(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cachePath, err := scaffold.ConfigCachePath(generator.ConfigURL())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := scaffold.SaveConfig(cachePath, tt.cached); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := loadGeneratorServiceConfig(context.Background(), p, generator)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Templates[0].Name != tt.expected {
+				t.Errorf("expected template %s, got %s", tt.expected, c.Templates[0].Name)
+			}
+			if fetched != tt.wantFetch {
+				t.Errorf("expected fetch = %v, got %v", tt.wantFetch, fetched)
+			}
+		})
+	}
+}
+
+func TestLoadGeneratorServiceConfigPerEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		template := strings.Trim(strings.TrimSuffix(r.URL.Path, "config"), "/")
+		w.Write([]byte("templates:\n- name: template" + template + "\nbomversions:\n- community: 2.1.3.RELEASE\n  snowdrop: 2.1.3-1\n  default: true\n"))
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"v1/config", "v2/config", "v1/config"} {
+		p := &scaffold.Project{UrlService: server.URL, ConfigPath: path, ConfigTTL: time.Hour}
+		generator, err := client.New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := loadGeneratorServiceConfig(context.Background(), p, generator)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "template" + strings.TrimSuffix(path, "/config"); c.Templates[0].Name != expected {
+			t.Errorf("expected configuration of %s to contain template %s, got %s", path, expected, c.Templates[0].Name)
+		}
+	}
+}
+
+func TestCompletionCmd(t *testing.T) {
+	tests := []struct {
+		shell   string
+		wantErr string
+	}{
+		{shell: "fish", wantErr: "fish completion is not supported: cobra v0.0.3"},
+		{shell: "powershell", wantErr: "unsupported shell 'powershell'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			root := &cobra.Command{Use: "scaffold", SilenceErrors: true, SilenceUsage: true}
+			root.AddCommand(newCompletionCmd())
+			root.SetArgs([]string{"completion", tt.shell})
+			err := root.Execute()
+			if err == nil || exitCode(err) != 2 || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected invalid input error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSensitiveParameters(t *testing.T) {
+	parameters := map[string][]string{"description": {"Demo"}, "dbPassword": {"s3cr3t"}, "GITHUB_TOKEN": {"ghp_s3cr3t"}}
+	if keys := sensitiveParameters(parameters); !reflect.DeepEqual([]string{"GITHUB_TOKEN", "dbPassword"}, keys) {
+		t.Errorf("expected [GITHUB_TOKEN dbPassword], got %v", keys)
+	}
+
+	// the reported generation URL doesn't disclose sensitive values
+	generator := &client.Client{URL: "https://generator.example.com"}
+	url := generator.GenerateURL((&scaffold.Project{ArtifactId: "demo", Parameters: parameters}).Redacted())
+	if strings.Contains(url, "s3cr3t") || !strings.Contains(url, "dbPassword="+scaffold.RedactedValue) || !strings.Contains(url, "description=Demo") {
+		t.Errorf("expected sensitive parameter values to be redacted, got %s", url)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestUnzipLargeArchiveReleasesDescriptors(t *testing.T) {
+	tmp := t.TempDir()
+
+	const count = 1000
+	entries := make([]zipEntry, count)
+	for i := range entries {
+		entries[i] = zipEntry{name: fmt.Sprintf("src/file%d.txt", i), mode: 0644, content: "content"}
+	}
+	zipFile := createZip(t, tmp, entries)
+
+	// lower the descriptors limit well below the number of entries so that leaking handles makes the extraction fail
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		t.Skipf("cannot read descriptors limit: %v", err)
+	}
+	lowered := limit
+	lowered.Cur = 256
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &lowered); err != nil {
+		t.Skipf("cannot lower descriptors limit: %v", err)
+	}
+	defer syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit)
+
+	dest := filepath.Join(tmp, "project")
+	if err := Unzip(zipFile, dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(dest, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != count {
+		t.Errorf("expected %d extracted files, got %d", count, len(files))
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// APIVersionHeader is the response header through which the generator service may report the version of its API, when it isn't
+// part of its configuration
+const APIVersionHeader = "X-Generator-Api-Version"
+
+// MinAPIVersion and MaxAPIVersion delimit the generator service API versions this client is compatible with, MaxAPIVersion being
+// excluded: a new major version may rename or drop the parameters used to generate projects
+const (
+	MinAPIVersion = "1.0"
+	MaxAPIVersion = "2.0"
+)
+
+// APIVersionError reports a generator service API version this client isn't compatible with
+type APIVersionError struct {
+	Version string
+}
+
+func (e *APIVersionError) Error() string {
+	return fmt.Sprintf("generator service API version %s is not compatible with this version of scaffold, which supports versions "+
+		"from %s and before %s: projects might be generated incorrectly, consider upgrading scaffold or using a compatible generator "+
+		"service", e.Version, MinAPIVersion, MaxAPIVersion)
+}
+
+// CheckAPIVersion checks that the specified generator service API version is compatible with this client, returning an
+// APIVersionError if it isn't or can't be parsed. An empty version, i.e. not reported by the service, is assumed to be compatible.
+func CheckAPIVersion(version string) error {
+	if len(version) == 0 {
+		return nil
+	}
+	parsed, err := parseAPIVersion(version)
+	if err != nil {
+		return &APIVersionError{Version: version}
+	}
+	min, _ := parseAPIVersion(MinAPIVersion)
+	max, _ := parseAPIVersion(MaxAPIVersion)
+	if compareAPIVersions(parsed, min) < 0 || compareAPIVersions(parsed, max) >= 0 {
+		return &APIVersionError{Version: version}
+	}
+	return nil
+}
+
+// parseAPIVersion parses the numeric components of the specified version, e.g. 1.2 or v1.2
+func parseAPIVersion(version string) ([]int, error) {
+	components := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	result := make([]int, len(components))
+	for i, component := range components {
+		n, err := strconv.Atoi(component)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid API version %s", version)
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// compareAPIVersions compares the specified parsed versions, missing components being considered as 0
+func compareAPIVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
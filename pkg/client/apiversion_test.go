@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/scaffold"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		compatible bool
+	}{
+		{version: "", compatible: true},
+		{version: "1", compatible: true},
+		{version: "1.0", compatible: true},
+		{version: "v1.4.2", compatible: true},
+		{version: "1.99", compatible: true},
+		{version: "0.9", compatible: false},
+		{version: "2", compatible: false},
+		{version: "2.0.0", compatible: false},
+		{version: "beta", compatible: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			err := CheckAPIVersion(tt.version)
+			var mismatch *APIVersionError
+			if tt.compatible && err != nil {
+				t.Errorf("expected %s to be compatible, got %v", tt.version, err)
+			} else if !tt.compatible && !errors.As(err, &mismatch) {
+				t.Errorf("expected %s to be reported as incompatible, got %v", tt.version, err)
+			}
+		})
+	}
+}
+
+func TestGetConfigAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		config   string
+		expected string
+	}{
+		{name: "none", config: "templates: []\n"},
+		{name: "header", header: "1.2", config: "templates: []\n", expected: "1.2"},
+		{name: "configuration", config: "apiversion: \"1.3\"\n", expected: "1.3"},
+		{name: "configuration preferred", header: "1.2", config: "apiversion: \"1.3\"\n", expected: "1.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(tt.header) > 0 {
+					w.Header().Set(APIVersionHeader, tt.header)
+				}
+				w.Write([]byte(tt.config))
+			}))
+			defer server.Close()
+
+			c, err := New(&scaffold.Project{UrlService: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := c.GetConfig(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.APIVersion != tt.expected {
+				t.Errorf("expected API version %q, got %q", tt.expected, config.APIVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+)
+
+const (
+	md5Header    = "Content-MD5"
+	sha256Header = "X-Checksum-SHA256"
+)
+
+// checksumReader computes the checksum of the content read from the underlying reader, reporting an error once all the content
+// has been read if it doesn't match the expected checksum
+type checksumReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+	header   string
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := r.hash.Sum(nil); !bytes.Equal(actual, r.expected) {
+			return n, fmt.Errorf("checksum mismatch: %s header announced %x but downloaded content has %x", r.header, r.expected, actual)
+		}
+	}
+	return n, err
+}
+
+// withChecksumVerification wraps the body of the specified response so that it's verified against the checksum provided by the
+// Content-MD5 (base64-encoded) or X-Checksum-SHA256 (hex-encoded) headers, if any
+func withChecksumVerification(res *http.Response) (io.ReadCloser, error) {
+	if value := res.Header.Get(sha256Header); len(value) > 0 {
+		expected, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %s: %v", sha256Header, value, err)
+		}
+		return &checksumReader{ReadCloser: res.Body, hash: sha256.New(), expected: expected, header: sha256Header}, nil
+	}
+
+	if value := res.Header.Get(md5Header); len(value) > 0 {
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %s: %v", md5Header, value, err)
+		}
+		return &checksumReader{ReadCloser: res.Body, hash: md5.New(), expected: expected, header: md5Header}, nil
+	}
+
+	return res.Body, nil
+}
@@ -0,0 +1,548 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/scaffold"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent header sent to the generator service unless another one is configured
+const DefaultUserAgent = "snowdrop-scaffold/1.0"
+
+// ErrCancelled is returned when an operation is interrupted by the user
+var ErrCancelled = errors.New("operation cancelled")
+
+// requestCount is the number of requests performed so far, used to identify requests in log events
+var requestCount uint64
+
+// retryBaseDelay is the delay before the first retry, subsequent retries waiting twice as long as the previous one
+var retryBaseDelay = 500 * time.Millisecond
+
+// Client provides access to the generator service exposed at URL
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	// Timeout is the time allowed to connect to the generator service and receive the headers of its responses
+	Timeout time.Duration
+	// Retries is the number of times failed requests are retried
+	Retries int
+	// UserAgent is the User-Agent header sent with every request, DefaultUserAgent if empty
+	UserAgent string
+	// Username and Password are the credentials used to authenticate with HTTP Basic Auth if Username is set
+	Username string
+	Password string
+	// Token is the bearer token used to authenticate if set, taking precedence over Username and Password
+	Token string
+	// SaveRequest is the path of the file to which requests and responses are appended for debugging purposes, if set
+	SaveRequest string
+	// Paths are the paths of the generator service endpoints relative to URL, DefaultPaths being used for the ones not set
+	Paths Paths
+}
+
+// Paths are the paths of the generator service endpoints, so that services exposing a different or versioned API can be used
+type Paths struct {
+	Config string
+	// Modules contains the VersionPlaceholder replaced by the Spring Boot version the modules are compatible with
+	Modules string
+	App     string
+}
+
+// VersionPlaceholder is replaced by the Spring Boot version in the modules endpoint path
+const VersionPlaceholder = "{version}"
+
+// DefaultPaths are the paths of the endpoints of the reference generator service
+var DefaultPaths = Paths{Config: "config", Modules: "modules/" + VersionPlaceholder, App: "app"}
+
+// withDefaults returns the paths, the default ones replacing the ones that aren't set
+func (p Paths) withDefaults() Paths {
+	if len(p.Config) == 0 {
+		p.Config = DefaultPaths.Config
+	}
+	if len(p.Modules) == 0 {
+		p.Modules = DefaultPaths.Modules
+	}
+	if len(p.App) == 0 {
+		p.App = DefaultPaths.App
+	}
+	return p
+}
+
+// validate makes sure that the paths can be used to compute the endpoint URLs
+func (p Paths) validate() error {
+	if !strings.Contains(p.Modules, VersionPlaceholder) {
+		return fmt.Errorf("modules endpoint path %s must contain %s, replaced by the Spring Boot version", p.Modules, VersionPlaceholder)
+	}
+	return nil
+}
+
+// New creates a Client for the generator service, timeout, proxy, TLS, retries, User-Agent and credentials configured for the
+// specified project
+func New(p *scaffold.Project) (*Client, error) {
+	tlsConfig, err := newTLSConfig(p.CACert, p.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHTTPClient(p.Timeout, p.Proxy, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := Paths{Config: p.ConfigPath, Modules: p.ModulesPath, App: p.AppPath}.withDefaults()
+	if err := paths.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		URL:         strings.TrimRight(p.UrlService, "/"),
+		HTTPClient:  httpClient,
+		Timeout:     p.Timeout,
+		Retries:     p.Retries,
+		UserAgent:   p.UserAgent,
+		Username:    p.Username,
+		Password:    p.Password,
+		Token:       p.Token,
+		SaveRequest: p.SaveRequest,
+		Paths:       paths,
+	}, nil
+}
+
+// GetConfig retrieves the generator service configuration
+func (c *Client) GetConfig(ctx context.Context) (*scaffold.Config, error) {
+	config := &scaffold.Config{}
+	header, err := c.getYaml(ctx, "config", c.paths().Config, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.APIVersion) == 0 {
+		config.APIVersion = header.Get(APIVersionHeader)
+	}
+	return config, nil
+}
+
+// GetModules retrieves the modules compatible with the specified Spring Boot version
+func (c *Client) GetModules(ctx context.Context, version string) ([]scaffold.Module, error) {
+	modules := []scaffold.Module{}
+	path := strings.Replace(c.paths().Modules, VersionPlaceholder, url.PathEscape(version), -1)
+	_, err := c.getYaml(ctx, "modules", path, &modules)
+	if err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
+
+// GenerateURL computes the URL that is called to generate the specified project
+func (c *Client) GenerateURL(p *scaffold.Project) string {
+	parameters := generateParameters(p).Encode()
+	if parameters != "" {
+		parameters = "?" + parameters
+	}
+
+	return c.endpoint(c.paths().App) + parameters
+}
+
+// Content is the content of a zipped project returned by the generator service
+type Content struct {
+	io.ReadCloser
+	// Length is the size of the content in bytes, -1 if unknown
+	Length int64
+}
+
+// Generate asks the generator service to generate the specified project, returning the content of the zipped project. Callers
+// are responsible for closing the returned content. If the service provides a checksum, reading the content will fail if it
+// doesn't match. Interrupted downloads are resumed up to c.Retries times.
+func (c *Client) Generate(ctx context.Context, p *scaffold.Project) (*Content, error) {
+	generateURL := c.GenerateURL(p)
+	res, err := c.get(ctx, "app", generateURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure we don't return an error page as a zip file
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return nil, statusError(c.URL, res.Request.URL.String(), res.StatusCode, fmt.Errorf("generator service returned %d: %s", res.StatusCode, body))
+	}
+
+	// the checksum covers the whole content, including the parts downloaded when resuming
+	res.Body = newResumableBody(ctx, c, generateURL, res)
+	content, err := withChecksumVerification(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return &Content{ReadCloser: content, Length: res.ContentLength}, nil
+}
+
+// generateParameters computes the query parameters sent to the generator service to generate the specified project. Template and
+// modules are mutually exclusive, which is enforced by the command, so the service never has to pick one over the other.
+func generateParameters(p *scaffold.Project) url.Values {
+	form := url.Values{}
+	// the template parameter is repeated when combining templates, and is always sent for compatibility with older services
+	if len(p.Template) == 0 {
+		form.Add("template", "")
+	}
+	for _, template := range p.Template {
+		form.Add("template", template)
+	}
+	form.Add("groupid", p.GroupId)
+	form.Add("artifactid", p.ArtifactId)
+	form.Add("version", p.Version)
+	form.Add("packagename", p.PackageName)
+	form.Add("snowdropbom", p.SnowdropBomVersion)
+	form.Add("springbootversion", p.SpringBootVersion)
+	// the service only needs the name of the project directory, not where it is located on the user's machine
+	outDir := p.OutDir
+	if len(outDir) > 0 {
+		outDir = filepath.Base(outDir)
+	}
+	form.Add("outdir", outDir)
+	form.Add("ap4k", strconv.FormatBool(p.UseAp4k))
+	form.Add("build", p.BuildTool)
+	form.Add("packaging", p.Packaging)
+	if len(p.JavaVersion) > 0 {
+		form.Add("javaversion", p.JavaVersion)
+	}
+	// never send blank modules, which the service would interpret as an unknown module
+	for _, v := range p.Modules {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			form.Add("module", v)
+		}
+	}
+	for k, values := range p.Parameters {
+		for _, v := range values {
+			form.Add(k, v)
+		}
+	}
+	return form
+}
+
+// ConfigURL returns the URL of the generator service configuration endpoint
+func (c *Client) ConfigURL() string {
+	return c.endpoint(c.paths().Config)
+}
+
+// paths returns the paths of the generator service endpoints, the default ones being used for the ones that aren't set
+func (c *Client) paths() Paths {
+	return c.Paths.withDefaults()
+}
+
+// endpoint computes the URL of the generator service endpoint at the specified path
+func (c *Client) endpoint(path string) string {
+	return strings.Join([]string{c.URL, strings.TrimLeft(path, "/")}, "/")
+}
+
+// getYaml unmarshals the YAML returned by the specified endpoint at the specified path into result, returning the headers of the
+// response
+func (c *Client) getYaml(ctx context.Context, endpoint, path string, result interface{}) (http.Header, error) {
+	res, err := c.get(ctx, endpoint, c.endpoint(path))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrCancelled
+		}
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{"endpoint": endpoint, "bytes": len(body)}).Debug("Read response body")
+
+	if err := c.checkAvailability(res, body); err != nil {
+		return nil, err
+	}
+
+	return res.Header, yaml.Unmarshal(body, result)
+}
+
+// checkYaml checks that the specified response, read into body, is not an HTML page, typically an error page returned by a reverse
+// proxy, which would otherwise result in a confusing unmarshalling error
+func checkYaml(res *http.Response, body []byte) error {
+	contentType := strings.ToLower(res.Header.Get("Content-Type"))
+	if strings.HasPrefix(contentType, "text/html") || bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		return fmt.Errorf("received unexpected non-YAML response from %s (status %d)", res.Request.URL, res.StatusCode)
+	}
+	return nil
+}
+
+// checkAvailability checks that the specified response, read into body, was actually returned by the generator service and not
+// by the platform hosting it, which is the case when the service is down or returns an error. Errors are typed according to the
+// status of the response.
+func (c *Client) checkAvailability(res *http.Response, body []byte) error {
+	if strings.Contains(string(body), "Application is not available") {
+		return &ServiceUnavailableError{URL: c.URL, StatusCode: res.StatusCode, Err: fmt.Errorf("generator service is not available at %s", c.URL)}
+	}
+	requestURL := res.Request.URL.String()
+	if err := checkYaml(res, body); err != nil {
+		return statusError(c.URL, requestURL, res.StatusCode, err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return statusError(c.URL, requestURL, res.StatusCode, fmt.Errorf("generator service at %s returned %s", c.URL, res.Status))
+	}
+	return nil
+}
+
+// get performs a GET request on the specified URL of the specified endpoint, retrying it if needed, until the specified context
+// is cancelled. Log events are tagged with a request identifier so that the events of a given request can be correlated.
+func (c *Client) get(ctx context.Context, endpoint, url string) (*http.Response, error) {
+	return c.getWithHeader(ctx, endpoint, url, nil)
+}
+
+// getWithHeader behaves like get but also sends the specified headers, e.g. to only request part of the content
+func (c *Client) getWithHeader(ctx context.Context, endpoint, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, strings.NewReader(""))
+	if err != nil {
+		return nil, err
+	}
+	c.addClientHeaders(req)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	logger := log.WithFields(log.Fields{
+		"request":  atomic.AddUint64(&requestCount, 1),
+		"endpoint": endpoint,
+		"url":      url,
+	})
+	logger.WithField("headers", redactedHeaders(req.Header)).Debug("Sending request")
+
+	start := time.Now()
+	res, attempts, err := c.doWithRetries(req, logger)
+	logger = logger.WithField("duration_ms", time.Since(start).Milliseconds())
+	if err == ErrCancelled {
+		logger.Debug("Request cancelled")
+		return nil, err
+	}
+	if err != nil {
+		logger.WithError(err).Debug("Request failed")
+		return nil, &ServiceUnavailableError{URL: c.URL, Err: fmt.Errorf("generator service unreachable at %s: %v", c.URL, err)}
+	}
+	logger.WithField("status", res.StatusCode).Debug("Received response")
+
+	if err := decodeBody(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if len(c.SaveRequest) > 0 {
+		// the body of generated projects is binary and potentially large so it is only saved when reporting an error
+		if err := saveExchange(c.SaveRequest, req, res, endpoint != "app" || res.StatusCode > 299); err != nil {
+			logger.WithError(err).Warnf("Couldn't save request to %s", c.SaveRequest)
+		}
+	}
+	if isRetryable(res.StatusCode) {
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		err := c.checkAvailability(res, body)
+		return nil, statusError(c.URL, url, res.StatusCode, fmt.Errorf("giving up after %d attempt(s): %v", attempts, err))
+	}
+	return res, nil
+}
+
+// addClientHeaders adds the headers identifying and authenticating the client to the specified request
+func (c *Client) addClientHeaders(req *http.Request) {
+	userAgent := c.UserAgent
+	if len(userAgent) == 0 {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	if len(c.Token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else if len(c.Username) > 0 {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// redactedHeaders returns a copy of the specified headers safe to be logged, i.e. without credentials
+func redactedHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	if len(redacted.Get("Authorization")) > 0 {
+		redacted.Set("Authorization", "[REDACTED]")
+	}
+	return redacted
+}
+
+// saveExchange appends the specified request, without credentials, and its response, including its body if requested, to the
+// file at the specified path
+func saveExchange(path string, req *http.Request, res *http.Response, body bool) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s\n", req.Method, req.URL)
+	if err := redactedHeaders(req.Header).Write(&buf); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+
+	dump, err := httputil.DumpResponse(res, body)
+	if err != nil {
+		return err
+	}
+	buf.Write(dump)
+	buf.WriteString("\n\n")
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// decodeBody transparently decompresses the body of the specified response if it is gzipped. The transport only does it when it
+// requested compression itself, which might not be the case, e.g. when compression is disabled or added by a proxy.
+func decodeBody(res *http.Response) error {
+	if !strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		return fmt.Errorf("invalid gzipped response from %s: %v", res.Request.URL, err)
+	}
+	res.Body = &gzipBody{Reader: reader, body: res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	return nil
+}
+
+// gzipBody decompresses a response body, closing it when closed
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// isRetryable checks whether a request failing with the specified status might succeed if retried, i.e. on server errors
+func isRetryable(statusCode int) bool {
+	return statusCode >= 500
+}
+
+// doWithRetries performs the specified request, retrying it up to c.Retries times with exponential backoff when it fails
+// because of network issues or server errors, logging attempts using the specified logger and returning the number of attempts
+// that were made. Only idempotent requests should be passed to this function.
+func (c *Client) doWithRetries(req *http.Request, logger *log.Entry) (*http.Response, int, error) {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		res, err := c.HTTPClient.Do(req)
+		if err == nil && !isRetryable(res.StatusCode) {
+			return res, attempt, nil
+		}
+		if req.Context().Err() != nil {
+			if err == nil {
+				res.Body.Close()
+			}
+			return nil, attempt, ErrCancelled
+		}
+
+		if attempt > c.Retries {
+			if err != nil {
+				return nil, attempt, fmt.Errorf("giving up after %d attempt(s): %v", attempt, requestError(req.URL.String(), c.Timeout, err))
+			}
+			logger.WithField("attempts", attempt).Debug("Giving up")
+			return res, attempt, nil
+		}
+
+		if err != nil {
+			logger.WithFields(log.Fields{"attempt": attempt, "retry_in": delay.String()}).WithError(err).Debug("Attempt failed")
+		} else {
+			logger.WithFields(log.Fields{"attempt": attempt, "retry_in": delay.String(), "status": res.StatusCode}).Debug("Attempt failed")
+			res.Body.Close()
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, attempt, ErrCancelled
+		}
+		delay *= 2
+	}
+}
+
+// newHTTPClient creates an http.Client giving up on requests if connecting or receiving the response headers takes longer than
+// the specified timeout and using the specified proxy if any or the proxy configured by the environment (HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY variables) otherwise, and the specified TLS configuration. Reading the body isn't limited so that large archives
+// can be downloaded over slow connections.
+func newHTTPClient(timeout time.Duration, proxy string, tlsConfig *tls.Config) (*http.Client, error) {
+	proxyFunc := http.ProxyFromEnvironment
+	if len(proxy) > 0 {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %s: %v", proxy, err)
+		}
+		proxyFunc = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 proxyFunc,
+			DialContext:           dialer.DialContext,
+			TLSClientConfig:       tlsConfig,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+	}, nil
+}
+
+// newTLSConfig creates the TLS configuration trusting the certificates of the specified PEM file in addition to the system ones,
+// or skipping verification altogether if insecure is true. A nil configuration, using the defaults, is returned otherwise.
+func newTLSConfig(caCert string, insecure bool) (*tls.Config, error) {
+	if len(caCert) == 0 && !insecure {
+		return nil, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: insecure}
+	if len(caCert) > 0 {
+		pem, err := ioutil.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read CA certificates: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid PEM certificate found in %s", caCert)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// requestError reports timeouts in a more user-friendly way than the underlying error, mentioning the endpoint that was called
+func requestError(url string, timeout time.Duration, err error) error {
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return fmt.Errorf("request to %s timed out after %s", url, timeout)
+	}
+	return err
+}
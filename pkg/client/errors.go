@@ -0,0 +1,67 @@
+package client
+
+import "net/http"
+
+// ServiceUnavailableError is returned when the generator service cannot be reached or is not able to process requests, which
+// might only be temporary
+type ServiceUnavailableError struct {
+	// URL is the URL of the generator service
+	URL string
+	// StatusCode is the status of the response, 0 if the service couldn't be reached at all
+	StatusCode int
+	Err        error
+}
+
+func (e *ServiceUnavailableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ServiceUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// BadRequestError is returned when the generator service rejects a request, typically because of invalid parameters
+type BadRequestError struct {
+	// URL is the URL of the rejected request
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e *BadRequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BadRequestError) Unwrap() error {
+	return e.Err
+}
+
+// NotFoundError is returned when the resource requested from the generator service doesn't exist, e.g. the modules of an
+// unknown Spring Boot version
+type NotFoundError struct {
+	// URL is the URL of the requested resource
+	URL string
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// statusError types the specified error, describing an unsuccessful response with the specified status to a request to the
+// specified URL of the generator service at serviceURL, according to the status
+func statusError(serviceURL, url string, statusCode int, err error) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return &NotFoundError{URL: url, Err: err}
+	case statusCode >= 500:
+		return &ServiceUnavailableError{URL: serviceURL, StatusCode: statusCode, Err: err}
+	case statusCode >= 400:
+		return &BadRequestError{URL: url, StatusCode: statusCode, Err: err}
+	}
+	return err
+}
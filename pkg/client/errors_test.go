@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/scaffold"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(err error) bool
+	}{
+		{name: "not found", status: http.StatusNotFound, body: "unknown version", check: func(err error) bool {
+			var e *NotFoundError
+			return errors.As(err, &e) && strings.HasSuffix(e.URL, "/modules/1.0.0")
+		}},
+		{name: "bad request", status: http.StatusBadRequest, body: "invalid version", check: func(err error) bool {
+			var e *BadRequestError
+			return errors.As(err, &e) && e.StatusCode == http.StatusBadRequest
+		}},
+		{name: "server error", status: http.StatusServiceUnavailable, body: "maintenance", check: func(err error) bool {
+			var e *ServiceUnavailableError
+			return errors.As(err, &e) && e.StatusCode == http.StatusServiceUnavailable
+		}},
+		{name: "platform error page", status: http.StatusOK, body: "<h1>Application is not available</h1>", check: func(err error) bool {
+			var e *ServiceUnavailableError
+			return errors.As(err, &e)
+		}},
+		{name: "proxy error page", status: http.StatusBadGateway, body: "<html>Bad Gateway</html>", check: func(err error) bool {
+			var e *ServiceUnavailableError
+			return errors.As(err, &e) && e.StatusCode == http.StatusBadGateway
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c, err := New(&scaffold.Project{UrlService: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.GetModules(context.Background(), "1.0.0")
+			if err == nil || !tt.check(err) {
+				t.Errorf("unexpected error type %T: %v", err, err)
+			}
+		})
+	}
+
+	// nothing listens on a closed server
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	c, err := New(&scaffold.Project{UrlService: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetConfig(context.Background())
+	var unavailable *ServiceUnavailableError
+	if !errors.As(err, &unavailable) || unavailable.StatusCode != 0 || unavailable.URL != server.URL {
+		t.Errorf("expected unreachable service to be reported as unavailable, got %T: %v", err, err)
+	}
+}
+
+func TestGenerateTypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unknown module", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := &scaffold.Project{UrlService: server.URL, Modules: []string{"unknown"}}
+	c, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Generate(context.Background(), p)
+	var badRequest *BadRequestError
+	if !errors.As(err, &badRequest) || !strings.Contains(badRequest.URL, "module=unknown") {
+		t.Errorf("expected rejected generation to be reported as a bad request, got %T: %v", err, err)
+	}
+}
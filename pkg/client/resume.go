@@ -0,0 +1,123 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// resumableBody reads the content of a generated project, resuming the download if it is interrupted. If the service supports
+// range requests and identifies the version of the content, only the missing part is requested, otherwise the whole content is
+// downloaded again and the part which was already read is skipped, after making sure that it didn't change.
+type resumableBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	client *Client
+	url    string
+	// ranges indicates whether the service supports range requests, i.e. announced Accept-Ranges: bytes
+	ranges bool
+	// validator identifies the version of the content (ETag or Last-Modified) so that it is only resumed if it didn't change
+	validator string
+	// length is the expected size of the content in bytes, -1 if unknown
+	length   int64
+	read     int64
+	attempts int
+	// digest is the hash of the content read so far, used to check that the content didn't change when downloading it again
+	digest hash.Hash
+}
+
+// newResumableBody wraps the body of the specified response, returned by the specified generate URL
+func newResumableBody(ctx context.Context, c *Client, url string, res *http.Response) io.ReadCloser {
+	validator := res.Header.Get("ETag")
+	if len(validator) == 0 {
+		validator = res.Header.Get("Last-Modified")
+	}
+	// ranges apply to the compressed content, not to the decompressed one which is read
+	_, gzipped := res.Body.(*gzipBody)
+	return &resumableBody{
+		ReadCloser: res.Body,
+		ctx:        ctx,
+		client:     c,
+		url:        url,
+		ranges:     strings.EqualFold(res.Header.Get("Accept-Ranges"), "bytes") && !gzipped && !res.Uncompressed,
+		validator:  validator,
+		length:     res.ContentLength,
+		digest:     sha256.New(),
+	}
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	b.digest.Write(p[:n])
+
+	switch {
+	case err == nil:
+		return n, nil
+	case err == io.EOF:
+		if b.length >= 0 && b.read != b.length {
+			return n, fmt.Errorf("incomplete download: received %d bytes out of %d", b.read, b.length)
+		}
+		return n, err
+	case b.ctx.Err() != nil || b.attempts >= b.client.Retries:
+		return n, err
+	}
+
+	logger := log.WithFields(log.Fields{"url": b.url, "bytes": b.read})
+	logger.WithError(err).Info("Download interrupted, resuming")
+	if resumeErr := b.resume(); resumeErr != nil {
+		logger.WithError(resumeErr).Debug("Couldn't resume download")
+		return n, err
+	}
+	return n, nil
+}
+
+// resume requests the content which hasn't been read yet
+func (b *resumableBody) resume() error {
+	b.attempts++
+	b.ReadCloser.Close()
+
+	// without validator, the missing part could belong to another version of the content since generated archives possibly differ
+	header := http.Header{}
+	if b.ranges && len(b.validator) > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", b.read))
+		header.Set("If-Range", b.validator)
+	}
+	res, err := b.client.getWithHeader(b.ctx, "app", b.url, header)
+	if err != nil {
+		return err
+	}
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		if _, err := fmt.Sscanf(res.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != b.read {
+			res.Body.Close()
+			return fmt.Errorf("unexpected Content-Range '%s' when resuming download at byte %d", res.Header.Get("Content-Range"), b.read)
+		}
+	case http.StatusOK:
+		// the whole content was sent again, which is only usable if it didn't change, generated archives possibly differing
+		digest := sha256.New()
+		if _, err := io.CopyN(digest, res.Body, b.read); err != nil {
+			res.Body.Close()
+			return err
+		}
+		if !bytes.Equal(digest.Sum(nil), b.digest.Sum(nil)) {
+			res.Body.Close()
+			return errors.New("generated content changed since the download started")
+		}
+	default:
+		res.Body.Close()
+		return statusError(b.client.URL, b.url, res.StatusCode, fmt.Errorf("generator service returned %d when resuming download", res.StatusCode))
+	}
+
+	b.ReadCloser = res.Body
+	return nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/scaffold"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResumeDownload(t *testing.T) {
+	content := []byte(strings.Repeat("zip content ", 1000))
+
+	tests := []struct {
+		name string
+		// ranges indicates whether the server supports range requests
+		ranges bool
+		// noValidator indicates whether the server doesn't identify the version of the content with an ETag
+		noValidator bool
+		// changed indicates whether the content sent when downloading again differs
+		changed bool
+		retries int
+		wantErr bool
+	}{
+		{name: "range requests", ranges: true, retries: 3},
+		{name: "full download", ranges: false, retries: 3},
+		{name: "changed content", ranges: false, changed: true, retries: 3, wantErr: true},
+		{name: "no retries", ranges: true, retries: 0, wantErr: true},
+		{name: "range requests without validator", ranges: true, noValidator: true, retries: 3},
+		{name: "changed content without validator", ranges: true, noValidator: true, changed: true, retries: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					// interrupt the first download halfway
+					w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+					if tt.ranges {
+						w.Header().Set("Accept-Ranges", "bytes")
+					}
+					if !tt.noValidator {
+						w.Header().Set("ETag", `"v1"`)
+					}
+					w.Write(content[:len(content)/2])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+
+				if !tt.ranges || tt.noValidator {
+					if len(r.Header.Get("Range")) > 0 {
+						t.Errorf("unexpected Range %s header without validator", r.Header.Get("Range"))
+					}
+					sent := content
+					if tt.changed {
+						sent = bytes.ToUpper(content)
+					}
+					w.Write(sent)
+					return
+				}
+				if r.Header.Get("Range") != "bytes="+strconv.Itoa(len(content)/2)+"-" || r.Header.Get("If-Range") != `"v1"` {
+					t.Errorf("unexpected Range %s and If-Range %s headers", r.Header.Get("Range"), r.Header.Get("If-Range"))
+				}
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+			}))
+			defer server.Close()
+
+			c, err := New(&scaffold.Project{UrlService: server.URL, Retries: tt.retries})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := c.Generate(context.Background(), &scaffold.Project{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer body.Close()
+
+			downloaded, err := ioutil.ReadAll(body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error = %v, but got = %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && !bytes.Equal(content, downloaded) {
+				t.Errorf("resumed download doesn't match the content: got %d bytes out of %d", len(downloaded), len(content))
+			}
+		})
+	}
+}
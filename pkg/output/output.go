@@ -0,0 +1,94 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is the format used to output the results of a command
+type Format string
+
+const (
+	// Table outputs human-readable aligned columns
+	Table Format = "table"
+	// JSON outputs machine-readable JSON
+	JSON Format = "json"
+	// YAML outputs machine-readable YAML
+	YAML Format = "yaml"
+)
+
+// Formats are the supported output formats, the first one being the default one
+var Formats = []Format{Table, JSON, YAML}
+
+// ParseFormat returns the output format with the specified name, the default one if the name is empty
+func ParseFormat(name string) (Format, error) {
+	if len(name) == 0 {
+		return Formats[0], nil
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		if Format(name) == format {
+			return format, nil
+		}
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unsupported output format '%s', supported ones are: %s", name, strings.Join(names, ", "))
+}
+
+// IsMachineReadable checks whether the format is intended to be consumed by scripts rather than read by users
+func (f Format) IsMachineReadable() bool {
+	return f != Table
+}
+
+// Renderer outputs the results of a command in a given format
+type Renderer struct {
+	Out    io.Writer
+	Format Format
+}
+
+// Render outputs the specified value in the machine-readable format of the renderer, or the specified headers and rows as aligned
+// columns in the table format
+func (r Renderer) Render(value interface{}, headers []string, rows [][]string) error {
+	switch r.Format {
+	case JSON:
+		return r.JSON(value)
+	case YAML:
+		return r.YAML(value)
+	default:
+		return PrintTable(r.Out, headers, rows)
+	}
+}
+
+// JSON outputs the indented JSON representation of the specified value
+func (r Renderer) JSON(value interface{}) error {
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(r.Out, string(b))
+	return err
+}
+
+// YAML outputs the YAML representation of the specified value, using the same keys as its JSON representation
+func (r Renderer) YAML(value interface{}) error {
+	b, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = r.Out.Write(b)
+	return err
+}
+
+// PrintTable outputs the specified rows as aligned columns, preceded by the specified headers
+func PrintTable(out io.Writer, headers []string, rows [][]string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
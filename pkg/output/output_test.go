@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Format
+		wantErr  bool
+	}{
+		{name: "", expected: Table},
+		{name: "table", expected: Table},
+		{name: "json", expected: JSON},
+		{name: "yaml", expected: YAML},
+		{name: "xml", wantErr: true},
+		{name: "JSON", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseFormat(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if format != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, format)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	type module struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags,omitempty"`
+	}
+	value := []module{{Name: "web", Tags: []string{"web"}}, {Name: "actuator"}}
+	headers := []string{"NAME", "DESCRIPTION"}
+	rows := [][]string{{"web", "Spring MVC"}, {"actuator", ""}}
+
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{format: Table, expected: "NAME      DESCRIPTION\nweb       Spring MVC\nactuator  \n"},
+		{format: JSON, expected: `[
+  {
+    "name": "web",
+    "tags": [
+      "web"
+    ]
+  },
+  {
+    "name": "actuator"
+  }
+]
+`},
+		{format: YAML, expected: `- name: web
+  tags:
+  - web
+- name: actuator
+`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := (Renderer{Out: &out, Format: tt.format}).Render(value, headers, rows); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package scaffold
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheDirName is the name of the directory, in the user's cache directory, where cached data is stored
+const cacheDirName = "snowdrop-scaffold"
+
+// CachedConfig is a generator service configuration cached along with the time it was retrieved at
+type CachedConfig struct {
+	FetchedAt time.Time `yaml:"fetchedat"  json:"fetchedat"`
+	Config    *Config   `yaml:"config"     json:"config"`
+}
+
+// IsFresh checks whether the cached configuration was retrieved less than the specified time ago
+func (c *CachedConfig) IsFresh(ttl time.Duration) bool {
+	return clock.Now().Sub(c.FetchedAt) < ttl
+}
+
+// ConfigCachePath returns the path of the file in which the generator service configuration retrieved from the specified URL,
+// i.e. the URL of the configuration endpoint, is cached
+func ConfigCachePath(url string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, cacheDirName, "config-"+hex.EncodeToString(hash[:8])+".yaml"), nil
+}
+
+// SaveConfig saves the specified configuration, retrieved now, as YAML in the file at the specified path, creating parent
+// directories if needed
+func SaveConfig(path string, c *Config) error {
+	content, err := yaml.Marshal(&CachedConfig{FetchedAt: clock.Now(), Config: c})
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// LoadConfig reads the configuration saved as YAML in the file at the specified path
+func LoadConfig(path string) (*CachedConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CachedConfig{}
+	err = yaml.Unmarshal(content, c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Config == nil {
+		return nil, fmt.Errorf("%s doesn't contain any configuration", path)
+	}
+	return c, nil
+}
@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadConfig(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	c := &Config{
+		Templates: []Template{{Name: "rest", Description: "REST service"}},
+		Boms:      []Bom{{Community: "2.1.3.RELEASE", Snowdrop: "2.1.3-1", Default: true}},
+	}
+
+	path := filepath.Join(tmp, "cache", "config.yaml")
+	err = SaveConfig(path, c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(c.Templates, loaded.Config.Templates) || !reflect.DeepEqual(c.Boms, loaded.Config.Boms) {
+		t.Errorf("expected %+v, got %+v", c, loaded.Config)
+	}
+	if !loaded.IsFresh(time.Minute) {
+		t.Errorf("configuration fetched at %s should be fresh", loaded.FetchedAt)
+	}
+	if loaded.IsFresh(0) {
+		t.Error("configuration shouldn't be fresh with a zero TTL")
+	}
+}
+
+func TestLoadConfigRejectsInvalidCache(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "config.yaml")
+	if err = ioutil.WriteFile(path, []byte("templates:\n- name: rest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadConfig(path); err == nil {
+		t.Error("cache without configuration should be rejected")
+	}
+}
+
+func TestConfigCachePath(t *testing.T) {
+	first, err := ConfigCachePath("https://generator.snowdrop.me")
+	if err != nil {
+		t.Skipf("no user cache directory: %v", err)
+	}
+	second, _ := ConfigCachePath("https://generator.example.com")
+	if first == second {
+		t.Error("configurations of different services should be cached separately")
+	}
+}
+
+// fakeClock is a Clock whose time only changes when advanced
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// useFakeClock replaces the clock of the package with a fake one until the test ends
+func useFakeClock(t *testing.T) *fakeClock {
+	fake := &fakeClock{now: time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	clock = fake
+	t.Cleanup(func() { clock = systemClock{} })
+	return fake
+}
+
+func TestCachedConfigExpiry(t *testing.T) {
+	fake := useFakeClock(t)
+
+	tmp, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "config.yaml")
+	if err = SaveConfig(path, &Config{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !loaded.FetchedAt.Equal(fake.now) {
+		t.Errorf("expected configuration to be fetched at %s, got %s", fake.now, loaded.FetchedAt)
+	}
+
+	ttl := 10 * time.Minute
+	fake.Advance(ttl - time.Second)
+	if !loaded.IsFresh(ttl) {
+		t.Error("configuration should still be fresh just before its TTL elapses")
+	}
+	fake.Advance(time.Second)
+	if loaded.IsFresh(ttl) {
+		t.Error("configuration should expire once its TTL elapses")
+	}
+}
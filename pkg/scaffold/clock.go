@@ -0,0 +1,18 @@
+package scaffold
+
+import "time"
+
+// Clock provides the current time, so that code depending on it can be tested without waiting
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock returning the actual current time
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clock is the Clock used by the package, only replaced for testing purposes
+var clock Clock = systemClock{}
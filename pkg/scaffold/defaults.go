@@ -0,0 +1,46 @@
+package scaffold
+
+import (
+	"github.com/ghodss/yaml"
+	"io/ioutil"
+)
+
+// Defaults holds the user-provided default values read from the configuration file, overriding the hardcoded defaults
+type Defaults struct {
+	UrlService  string `json:"urlservice,omitempty"`
+	GroupId     string `json:"groupid,omitempty"`
+	Version     string `json:"version,omitempty"`
+	PackageName string `json:"packagename,omitempty"`
+	// ConfigPath, ModulesPath and AppPath are the paths of the endpoints of a generator service exposing a different API
+	ConfigPath  string `json:"configendpoint,omitempty"`
+	ModulesPath string `json:"modulesendpoint,omitempty"`
+	AppPath     string `json:"appendpoint,omitempty"`
+}
+
+// LoadDefaults reads the YAML configuration file at the specified path
+func LoadDefaults(path string) (*Defaults, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Defaults{}
+	err = yaml.Unmarshal(content, d)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// AsFlags associates the name of the flags with the default value they should get
+func (d *Defaults) AsFlags() map[string]string {
+	return map[string]string{
+		"urlservice":       d.UrlService,
+		"groupid":          d.GroupId,
+		"version":          d.Version,
+		"packagename":      d.PackageName,
+		"config-endpoint":  d.ConfigPath,
+		"modules-endpoint": d.ModulesPath,
+		"app-endpoint":     d.AppPath,
+	}
+}
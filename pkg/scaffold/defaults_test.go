@@ -0,0 +1,38 @@
+package scaffold
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, ".scaffoldrc")
+	err = ioutil.WriteFile(path, []byte("urlservice: http://localhost:8080\ngroupid: me.snowdrop\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDefaults(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.UrlService != "http://localhost:8080" || d.GroupId != "me.snowdrop" {
+		t.Errorf("unexpected defaults: %+v", d)
+	}
+	if len(d.Version) > 0 || len(d.PackageName) > 0 {
+		t.Errorf("values not present in the file should be empty: %+v", d)
+	}
+
+	_, err = LoadDefaults(filepath.Join(tmp, "missing"))
+	if !os.IsNotExist(err) {
+		t.Errorf("loading a missing file should report it doesn't exist, got: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ghodss/yaml"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces the values of sensitive parameters in exported specs and reported outcomes
+const RedactedValue = "REDACTED"
+
+// sensitiveKeywords identify the parameters likely holding secrets, whose key contains one of them ignoring case
+var sensitiveKeywords = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api-key", "api_key", "private"}
+
+// LoadProject reads the project spec, either in YAML or JSON, at the specified path. Unknown fields are rejected so that typos
+// don't silently result in a different project.
+func LoadProject(path string) (*Project, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON being a subset of YAML, converting the content to JSON handles both formats
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Project{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SaveProject writes the spec of the specified project to the file at the specified path in YAML so that it can be created again
+// using LoadProject. Credentials are never saved, including the ones that might be part of the service URL, and the values of
+// sensitive parameters are replaced by RedactedValue.
+func SaveProject(path string, p *Project) error {
+	content, err := yaml.Marshal(p.Redacted())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// Redacted returns a copy of the project which can safely be saved or displayed, i.e. without the credentials that might be part
+// of the service URL and with the values of sensitive parameters replaced by RedactedValue
+func (p *Project) Redacted() *Project {
+	redacted := *p
+	if u, err := url.Parse(redacted.UrlService); err == nil && u.User != nil {
+		u.User = nil
+		redacted.UrlService = u.String()
+	}
+	if len(p.Parameters) > 0 {
+		redacted.Parameters = make(map[string][]string, len(p.Parameters))
+		for key, values := range p.Parameters {
+			if IsSensitiveParameter(key) {
+				values = []string{RedactedValue}
+			}
+			redacted.Parameters[key] = values
+		}
+	}
+	return &redacted
+}
+
+// IsSensitiveParameter checks whether the parameter with the specified key likely holds a secret such as a password or a token
+func IsSensitiveParameter(key string) bool {
+	key = strings.ToLower(key)
+	for _, keyword := range sensitiveKeywords {
+		if strings.Contains(key, keyword) {
+			return true
+		}
+	}
+	return false
+}
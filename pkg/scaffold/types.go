@@ -1,28 +1,140 @@
 package scaffold
 
-import "sort"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 type Project struct {
-	GroupId     string
-	ArtifactId  string
-	Version     string
-	PackageName string
-	OutDir      string
-	Template    string `yaml:"template"  json:"template"`
+	GroupId     string        `yaml:"groupid"      json:"groupid"`
+	ArtifactId  string        `yaml:"artifactid"   json:"artifactid"`
+	Version     string        `yaml:"version"      json:"version"`
+	PackageName string        `yaml:"packagename"  json:"packagename"`
+	OutDir      string        `yaml:"outdir"       json:"outdir"`
+	BuildTool   string        `yaml:"build"        json:"build"`
+	JavaVersion string        `yaml:"javaversion,omitempty"  json:"javaversion,omitempty"`
+	Packaging   string        `yaml:"packaging,omitempty"    json:"packaging,omitempty"`
+	Template    TemplateNames `yaml:"template"     json:"template"`
 
-	SnowdropBomVersion string
-	SpringBootVersion  string
-	Modules            []string
+	SnowdropBomVersion string   `yaml:"snowdropbom"        json:"snowdropbom"`
+	SpringBootVersion  string   `yaml:"springbootversion"  json:"springbootversion"`
+	Modules            []string `yaml:"modules"            json:"modules"`
 
-	UrlService   string
-	UseAp4k      bool
-	UseSupported bool
+	// Parameters are additional parameters passed as is to the generator service
+	Parameters map[string][]string `yaml:"parameters,omitempty"  json:"parameters,omitempty"`
+
+	UrlService   string        `yaml:"urlservice"  json:"urlservice"`
+	Timeout      time.Duration `yaml:"-"           json:"-"`
+	Retries      int           `yaml:"-"           json:"-"`
+	Proxy        string        `yaml:"-"           json:"-"`
+	Offline      bool          `yaml:"-"           json:"-"`
+	ConfigTTL    time.Duration `yaml:"-"           json:"-"`
+	NoCache      bool          `yaml:"-"           json:"-"`
+	CACert       string        `yaml:"-"           json:"-"`
+	Insecure     bool          `yaml:"-"           json:"-"`
+	UserAgent    string        `yaml:"-"           json:"-"`
+	Username     string        `yaml:"-"           json:"-"`
+	Password     string        `yaml:"-"           json:"-"`
+	Token        string        `yaml:"-"           json:"-"`
+	SaveRequest  string        `yaml:"-"           json:"-"`
+	Strict       bool          `yaml:"-"           json:"-"`
+	ConfigPath   string        `yaml:"-"           json:"-"`
+	ModulesPath  string        `yaml:"-"           json:"-"`
+	AppPath      string        `yaml:"-"           json:"-"`
+	UseAp4k      bool          `yaml:"ap4k"        json:"ap4k"`
+	UseSupported bool          `yaml:"supported"   json:"supported"`
 }
 
 type Config struct {
 	Templates []Template `yaml:"templates"    json:"templates"`
 	Boms      []Bom      `yaml:"bomversions"  json:"bomversions"`
 	Modules   []Module   `yaml:"modules"      json:"modules"`
+	// JavaVersions are the Java versions supported by the generator service, the first one being the default one
+	JavaVersions []string `yaml:"javaversions,omitempty"  json:"javaversions,omitempty"`
+	// ComposableTemplates indicates whether the generator service supports creating a project from several templates
+	ComposableTemplates bool `yaml:"composabletemplates,omitempty"  json:"composabletemplates,omitempty"`
+	// APIVersion is the version of the API exposed by the generator service, empty if it doesn't report it
+	APIVersion string `yaml:"apiversion,omitempty"  json:"apiversion,omitempty"`
+}
+
+// TemplateNames are the names of the templates a project is created from. A single template is (un)marshalled as a plain string
+// so that existing specs and machine-readable output remain compatible.
+type TemplateNames []string
+
+func (t TemplateNames) String() string {
+	return strings.Join(t, ", ")
+}
+
+func (t TemplateNames) MarshalJSON() ([]byte, error) {
+	switch len(t) {
+	case 0:
+		return json.Marshal("")
+	case 1:
+		return json.Marshal(t[0])
+	default:
+		return json.Marshal([]string(t))
+	}
+}
+
+func (t *TemplateNames) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = nil
+		if len(name) > 0 {
+			*t = TemplateNames{name}
+		}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// defaultJavaVersions are the Java versions offered when the generator service doesn't specify the ones it supports
+var defaultJavaVersions = []string{"8", "11", "17", "21"}
+
+// GetJavaVersions returns the Java versions supported by the generator service, or commonly supported ones if it doesn't
+// specify them, the first one being the default one
+func (c *Config) GetJavaVersions() []string {
+	if len(c.JavaVersions) > 0 {
+		return c.JavaVersions
+	}
+	return defaultJavaVersions
+}
+
+// Validate checks that the configuration provides what is needed to create projects, i.e. a templates list and at least one BOM,
+// one of them being the default one, reporting everything that is missing at once
+func (c *Config) Validate() error {
+	problems := []string{}
+	if c.Templates == nil {
+		problems = append(problems, "no templates list")
+	}
+	if len(c.Boms) == 0 {
+		problems = append(problems, "no Spring Boot versions")
+	}
+	hasDefault := false
+	for i, v := range c.Boms {
+		if len(v.Community) == 0 {
+			problems = append(problems, fmt.Sprintf("no Spring Boot version for BOM #%d", i+1))
+		} else if len(v.Snowdrop) == 0 {
+			problems = append(problems, "no Snowdrop BOM version for Spring Boot "+v.Community)
+		}
+		hasDefault = hasDefault || v.Default
+	}
+	if len(c.Boms) > 0 && !hasDefault {
+		problems = append(problems, "no default Spring Boot version")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("generator service configuration is invalid: %s", strings.Join(problems, ", "))
+	}
+	return nil
 }
 
 func (c *Config) GetTemplatesMap() map[string]Template {
@@ -44,6 +156,15 @@ func (c *Config) GetTemplateNames() []string {
 	return result
 }
 
+// GetTemplateDescriptions returns the descriptions of the templates indexed by template name
+func (c *Config) GetTemplateDescriptions() map[string]string {
+	result := make(map[string]string, len(c.Templates))
+	for _, value := range c.Templates {
+		result[value.Name] = value.Description
+	}
+	return result
+}
+
 func (c *Config) GetModuleNames() []string {
 	return GetModuleNamesFor(c.Modules)
 }
@@ -57,6 +178,81 @@ func GetModuleNamesFor(modules []Module) []string {
 	return result
 }
 
+// GetModuleDescriptionsFor returns the descriptions of the specified modules indexed by module name
+func GetModuleDescriptionsFor(modules []Module) map[string]string {
+	result := make(map[string]string, len(modules))
+	for _, v := range modules {
+		result[v.Name] = v.Description
+	}
+	return result
+}
+
+// GetModuleCategoriesFor returns the main categories of the specified modules indexed by module name, uncategorized modules being
+// omitted
+func GetModuleCategoriesFor(modules []Module) map[string]string {
+	result := make(map[string]string, len(modules))
+	for _, v := range modules {
+		if category := v.Category(); len(category) > 0 {
+			result[v.Name] = category
+		}
+	}
+	return result
+}
+
+// GetCategoriesFor returns the sorted categories the specified modules belong to
+func GetCategoriesFor(modules []Module) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, v := range modules {
+		for _, tag := range v.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				result = append(result, tag)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// FilterModulesByCategory returns the specified modules belonging to the specified category
+func FilterModulesByCategory(modules []Module, category string) []Module {
+	result := make([]Module, 0, len(modules))
+	for _, v := range modules {
+		if v.HasCategory(category) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// GetConflicts returns the conflicts between the specified selected modules, according to the modules of the configuration
+func (c *Config) GetConflicts(selected []string) []Conflict {
+	return GetConflictsFor(c.Modules, selected)
+}
+
+// GetConflictsFor returns the conflicts between the specified selected modules, according to the specified modules. A conflict
+// declared by either module is reported once, in selection order.
+func GetConflictsFor(modules []Module, selected []string) []Conflict {
+	conflicting := make(map[Conflict]bool)
+	for _, v := range modules {
+		for _, other := range v.Conflicts {
+			conflicting[Conflict{Module: v.Name, With: other}] = true
+			conflicting[Conflict{Module: other, With: v.Name}] = true
+		}
+	}
+
+	result := []Conflict{}
+	for i, module := range selected {
+		for _, other := range selected[i+1:] {
+			if conflicting[Conflict{Module: module, With: other}] {
+				result = append(result, Conflict{Module: module, With: other})
+			}
+		}
+	}
+	return result
+}
+
 func (c *Config) GetBOMMap() (map[string]Bom, string) {
 	var defaultVersion string
 	result := make(map[string]Bom, len(c.Boms))
@@ -109,7 +305,38 @@ type Module struct {
 	Description  string       `yaml:"description"      json:"description"`
 	Guide        string       `yaml:"guide_ref"        json:"guide_ref"`
 	Dependencies []Dependency `yaml:"dependencies"     json:"dependencies"`
-	tags         []string     `yaml:"tags"             json:"tags"`
+	// Tags are the categories of the module, the first one being its main category
+	Tags []string `yaml:"tags,omitempty"   json:"tags,omitempty"`
+	// Conflicts are the names of the modules that cannot be used along with this one, e.g. another web server
+	Conflicts []string `yaml:"conflicts,omitempty"  json:"conflicts,omitempty"`
+}
+
+// Conflict is a pair of mutually exclusive modules
+type Conflict struct {
+	Module string
+	With   string
+}
+
+func (c Conflict) String() string {
+	return c.Module + " and " + c.With
+}
+
+// Category returns the main category of the module, empty if it isn't categorized
+func (m Module) Category() string {
+	if len(m.Tags) == 0 {
+		return ""
+	}
+	return m.Tags[0]
+}
+
+// HasCategory checks whether the module belongs to the specified category, ignoring case
+func (m Module) HasCategory(category string) bool {
+	for _, tag := range m.Tags {
+		if strings.EqualFold(tag, category) {
+			return true
+		}
+	}
+	return false
 }
 
 type Dependency struct {
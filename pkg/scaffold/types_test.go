@@ -0,0 +1,138 @@
+package scaffold
+
+import (
+	"github.com/ghodss/yaml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestModuleCategories(t *testing.T) {
+	modules := []Module{}
+	err := yaml.Unmarshal([]byte(`
+- name: web
+  tags: [web]
+- name: jpa
+  tags: [data, sql]
+- name: mongodb
+  tags: [data, nosql]
+- name: core
+`), &modules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if categories := GetCategoriesFor(modules); !reflect.DeepEqual([]string{"data", "nosql", "sql", "web"}, categories) {
+		t.Errorf("unexpected categories: %v", categories)
+	}
+
+	expected := map[string]string{"web": "web", "jpa": "data", "mongodb": "data"}
+	if categories := GetModuleCategoriesFor(modules); !reflect.DeepEqual(expected, categories) {
+		t.Errorf("expected main categories %v, got %v", expected, categories)
+	}
+
+	if filtered := GetModuleNamesFor(FilterModulesByCategory(modules, "Data")); !reflect.DeepEqual([]string{"jpa", "mongodb"}, filtered) {
+		t.Errorf("expected modules in data category, got %v", filtered)
+	}
+	if filtered := FilterModulesByCategory(modules, "unknown"); len(filtered) != 0 {
+		t.Errorf("expected no module in unknown category, got %v", filtered)
+	}
+}
+
+func TestGetConflicts(t *testing.T) {
+	c := &Config{Modules: []Module{
+		{Name: "web", Conflicts: []string{"webflux"}},
+		{Name: "webflux"},
+		{Name: "undertow", Conflicts: []string{"tomcat", "jetty"}},
+		{Name: "jetty", Conflicts: []string{"undertow"}},
+	}}
+
+	tests := []struct {
+		selected []string
+		expected []Conflict
+	}{
+		{selected: []string{"web", "jpa"}, expected: []Conflict{}},
+		{selected: []string{"webflux", "web"}, expected: []Conflict{{Module: "webflux", With: "web"}}},
+		{selected: []string{"jetty", "web", "undertow"}, expected: []Conflict{{Module: "jetty", With: "undertow"}}},
+	}
+	for _, tt := range tests {
+		if conflicts := c.GetConflicts(tt.selected); !reflect.DeepEqual(tt.expected, conflicts) {
+			t.Errorf("expected %v for %v, got %v", tt.expected, tt.selected, conflicts)
+		}
+	}
+}
+
+func TestTemplateNames(t *testing.T) {
+	tests := []struct {
+		yaml     string
+		expected TemplateNames
+	}{
+		{yaml: `template: ""`, expected: nil},
+		{yaml: `template: rest`, expected: TemplateNames{"rest"}},
+		{yaml: `template: [rest, crud]`, expected: TemplateNames{"rest", "crud"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.yaml, func(t *testing.T) {
+			p := &Project{}
+			if err := yaml.Unmarshal([]byte(tt.yaml), p); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.expected, p.Template) {
+				t.Errorf("expected %v, got %v", tt.expected, p.Template)
+			}
+
+			// a single template is marshalled as a plain string so that the result can be read by older versions
+			content, err := yaml.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			roundTripped := &Project{}
+			if err := yaml.Unmarshal(content, roundTripped); err != nil || !reflect.DeepEqual(p.Template, roundTripped.Template) {
+				t.Errorf("expected %v after round trip, got %v (%v)", p.Template, roundTripped.Template, err)
+			}
+		})
+	}
+
+	content, err := yaml.Marshal(&Project{Template: TemplateNames{"rest"}})
+	if err != nil || !strings.Contains(string(content), "template: rest\n") {
+		t.Errorf("expected single template to be marshalled as a string, got %s (%v)", content, err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:   "valid",
+			config: "templates: []\nbomversions:\n- community: 2.1.3.RELEASE\n  snowdrop: 2.1.3-1\n  default: true\n",
+		},
+		{
+			name:     "empty",
+			config:   "modules: []\n",
+			expected: "generator service configuration is invalid: no templates list, no Spring Boot versions",
+		},
+		{
+			name:     "incomplete BOMs",
+			config:   "templates: []\nbomversions:\n- community: 2.1.3.RELEASE\n- snowdrop: 2.1.3-1\n",
+			expected: "generator service configuration is invalid: no Snowdrop BOM version for Spring Boot 2.1.3.RELEASE, no Spring Boot version for BOM #2, no default Spring Boot version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if err := yaml.Unmarshal([]byte(tt.config), c); err != nil {
+				t.Fatal(err)
+			}
+			err := c.Validate()
+			if len(tt.expected) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(tt.expected) > 0 && (err == nil || err.Error() != tt.expected) {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
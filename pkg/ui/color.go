@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"github.com/mgutz/ansi"
+	terminal2 "golang.org/x/crypto/ssh/terminal"
+	"io"
+	"os"
+)
+
+// noColorEnvVar is the environment variable disabling colors when set, whatever its value, see https://no-color.org
+const noColorEnvVar = "NO_COLOR"
+
+// Success styles the specified message, meant to be written to the specified output, as a success message
+func Success(out io.Writer, message string) string {
+	return colorize(ansi.Green, message, colorsEnabled(out))
+}
+
+// Warning styles the specified message, meant to be written to the specified output, as a warning
+func Warning(out io.Writer, message string) string {
+	return colorize(ansi.Yellow, message, colorsEnabled(out))
+}
+
+// Failure styles the specified message, meant to be written to the specified output, as an error
+func Failure(out io.Writer, message string) string {
+	return colorize(ansi.Red, message, colorsEnabled(out))
+}
+
+// colorsEnabled checks whether colors can be used when writing to the specified output, i.e. if it is a terminal and colors were
+// not disabled using the NO_COLOR environment variable
+func colorsEnabled(out io.Writer) bool {
+	if _, disabled := os.LookupEnv(noColorEnvVar); disabled {
+		return false
+	}
+	return isTerminal(out)
+}
+
+// isTerminal checks whether the specified output is a terminal
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && terminal2.IsTerminal(int(f.Fd()))
+}
+
+// colorize displays the specified message using the specified color if enabled
+func colorize(color, message string, enabled bool) string {
+	if !enabled {
+		return message
+	}
+	return color + message + ansi.Reset
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"github.com/mgutz/ansi"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestColorize(t *testing.T) {
+	if colored := colorize(ansi.Green, "done", true); colored != ansi.Green+"done"+ansi.Reset {
+		t.Errorf("expected colored message, got %q", colored)
+	}
+	if plain := colorize(ansi.Green, "done", false); plain != "done" {
+		t.Errorf("expected plain message, got %q", plain)
+	}
+}
+
+func TestColorsEnabled(t *testing.T) {
+	f, err := ioutil.TempFile("", "color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if colorsEnabled(f) {
+		t.Error("colors should be disabled when not writing to a terminal")
+	}
+
+	t.Setenv(noColorEnvVar, "")
+	if colorsEnabled(os.Stdout) {
+		t.Error("colors should be disabled when NO_COLOR is set, even if empty")
+	}
+	if Success(os.Stdout, "done") != "done" || Failure(os.Stderr, "failed") != "failed" || Warning(os.Stdout, "careful") != "careful" {
+		t.Error("messages should not be styled when NO_COLOR is set")
+	}
+}
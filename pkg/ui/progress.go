@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressBarWidth = 30
+	progressInterval = 100 * time.Millisecond
+)
+
+// StartProgress displays on the specified output the specified message along with the progress of reading the returned reader,
+// which wraps the specified one, until the returned function is called. A progress bar is displayed if the total number of bytes
+// is known (i.e. positive), the number of bytes read otherwise. Nothing is displayed if the output is not a terminal.
+func StartProgress(out io.Writer, message string, r io.Reader, total int64) (io.Reader, func()) {
+	if !isTerminal(out) {
+		return r, func() {}
+	}
+	return startProgress(message, r, total, out)
+}
+
+// startProgress displays progress on the specified output (useful for testing purposes)
+func startProgress(message string, r io.Reader, total int64, out io.Writer) (io.Reader, func()) {
+	p := &progressReader{reader: r, message: message, total: total, out: out}
+	var once sync.Once
+	return p, func() {
+		once.Do(func() {
+			p.render(true)
+			fmt.Fprintln(out)
+		})
+	}
+}
+
+// progressReader renders the progress of reading the wrapped reader at most every progressInterval
+type progressReader struct {
+	reader     io.Reader
+	message    string
+	total      int64
+	read       int64
+	out        io.Writer
+	lastRender time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.read += int64(n)
+	p.render(false)
+	return n, err
+}
+
+// render outputs the current progress, unless it was already rendered recently and force is false
+func (p *progressReader) render(force bool) {
+	now := time.Now()
+	if !force && now.Sub(p.lastRender) < progressInterval {
+		return
+	}
+	p.lastRender = now
+
+	if p.total <= 0 {
+		fmt.Fprintf(p.out, "\r%s %s", p.message, formatBytes(p.read))
+		return
+	}
+
+	read := p.read
+	if read > p.total {
+		read = p.total
+	}
+	filled := int(read * progressBarWidth / p.total)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	fmt.Fprintf(p.out, "\r%s [%s] %3d%% %s / %s", p.message, bar, read*100/p.total, formatBytes(read), formatBytes(p.total))
+}
+
+// formatBytes formats the specified number of bytes in a human-readable way
+func formatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
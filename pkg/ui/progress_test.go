@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		expected string
+	}{
+		{name: "known length", total: 2048, expected: "Downloading [==============================] 100% 2.0 KB / 2.0 KB\n"},
+		{name: "unknown length", total: -1, expected: "Downloading 2.0 KB\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			content := strings.Repeat("x", 2048)
+			r, stop := startProgress("Downloading", strings.NewReader(content), tt.total, &out)
+
+			read, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(read) != content {
+				t.Error("progress reader should return the wrapped content as is")
+			}
+			stop()
+			stop()
+
+			lines := strings.Split(out.String(), "\r")
+			if last := lines[len(lines)-1]; last != tt.expected {
+				t.Errorf("expected final progress %q, got %q", tt.expected, last)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{bytes: 512, expected: "512 B"},
+		{bytes: 1536, expected: "1.5 KB"},
+		{bytes: 5 << 20, expected: "5.0 MB"},
+	}
+	for _, tt := range tests {
+		if formatted := formatBytes(tt.bytes); formatted != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, formatted)
+		}
+	}
+}
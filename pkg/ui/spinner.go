@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// StartSpinner displays the specified message along with a spinner on the specified output until the returned function is called.
+// Nothing is displayed if the output is not a terminal.
+func StartSpinner(out io.Writer, message string) (stop func()) {
+	if !isTerminal(out) {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(out, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], message)
+			select {
+			case <-done:
+				// erase the spinner line
+				fmt.Fprintf(out, "\r%*s\r", len(message)+2, "")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import "errors"
+
+// GoBack is the option offered by prompts allowing the user to go back to the previous step
+const GoBack = "← Go back"
+
+// ErrGoBack is returned by prompts when the user selects the GoBack option
+var ErrGoBack = errors.New("go back to the previous step")
+
+// Step is a step of an interactive flow. canGoBack indicates whether the step can offer to go back to a previous step, which it
+// does by returning ErrGoBack. The step returns whether the user can go back to it, i.e. whether it prompted the user using a
+// prompt that also offers to go back.
+type Step func(canGoBack bool) (revisitable bool, err error)
+
+// RunSteps runs the specified steps in order until one of them fails. When a step returns ErrGoBack, the flow resumes from the
+// closest previous revisitable step, running all the steps following it again, or from the same step if there is none.
+func RunSteps(steps ...Step) error {
+	// indexes of the revisitable steps that have been run, in order
+	history := make([]int, 0, len(steps))
+	for i := 0; i < len(steps); {
+		revisitable, err := steps[i](len(history) > 0)
+		if err == ErrGoBack {
+			// run the current step again if there is no step to go back to
+			if len(history) > 0 {
+				i = history[len(history)-1]
+				history = history[:len(history)-1]
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if revisitable {
+			history = append(history, i)
+		}
+		i++
+	}
+	return nil
+}
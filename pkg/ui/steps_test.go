@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRunSteps(t *testing.T) {
+	var calls []string
+	goBack := true
+	step := func(name string, revisitable bool) Step {
+		return func(canGoBack bool) (bool, error) {
+			calls = append(calls, name)
+			// the last step goes back once
+			if name == "c" && goBack {
+				goBack = false
+				if !canGoBack {
+					t.Error("last step should be able to go back")
+				}
+				return false, ErrGoBack
+			}
+			return revisitable, nil
+		}
+	}
+
+	err := RunSteps(step("a", true), step("b", false), step("c", true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// going back skips b since it isn't revisitable, but runs it again after a
+	if expected := []string{"a", "b", "c", "a", "b", "c"}; !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected steps %v to be run, got %v", expected, calls)
+	}
+}
+
+func TestRunStepsWithoutRevisitableStep(t *testing.T) {
+	calls := 0
+	err := RunSteps(func(canGoBack bool) (bool, error) {
+		calls++
+		if canGoBack {
+			t.Error("first step shouldn't be able to go back")
+		}
+		if calls == 1 {
+			return false, ErrGoBack
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("step should have been run again, got %d calls", calls)
+	}
+
+	failure := errors.New("failure")
+	if err = RunSteps(func(bool) (bool, error) { return false, failure }); err != failure {
+		t.Errorf("expected step error to be returned, got %v", err)
+	}
+}
@@ -6,37 +6,98 @@ import (
 	"github.com/snowdrop/odo-scaffold-plugin/pkg/validation"
 	"gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	"io"
 	"os"
 	"sort"
+	"strings"
 )
 
-// HandleError handles UI-related errors, in particular useful to gracefully handle ctrl-c interrupts gracefully
+// exit terminates the process with the specified status code, replaceable for testing purposes
+var exit = os.Exit
+
+// HandleError handles UI-related errors, in particular useful to gracefully handle ctrl-c interrupts. Since the response of a
+// failed prompt cannot be used, the process exits with a non-zero status code on any error, after reporting it unless the
+// user interrupted the prompt.
 func HandleError(err error) {
-	if err != nil {
-		if err == terminal.InterruptErr {
-			os.Exit(1)
-		} else {
-			fmt.Printf("Encountered an error processing prompt: %v", err)
-		}
+	if err == nil {
+		return
+	}
+	if err != terminal.InterruptErr {
+		fmt.Fprintln(os.Stderr, Failure(os.Stderr, fmt.Sprintf("Encountered an error processing prompt: %v", err)))
 	}
+	exit(1)
 }
 
-// Proceed displays a given message and asks the user if they want to proceed
+// Proceed displays a given message and asks the user if they want to proceed, exiting the process if the prompt fails. Use
+// ProceedE to handle errors.
 func Proceed(message string) bool {
+	response, err := ProceedE(message)
+	HandleError(err)
+	return response
+}
+
+// ProceedE displays a given message and asks the user if they want to proceed, returning terminal.InterruptErr if the user
+// interrupts the prompt
+func ProceedE(message string) (bool, error) {
+	return proceed(message)
+}
+
+// proceed asks the user if they want to proceed using the specified Stdio instance (useful for testing purposes)
+func proceed(message string, stdio ...terminal.Stdio) (bool, error) {
 	var response bool
 	prompt := &survey.Confirm{
 		Message: message,
 		Default: true,
 	}
 
-	err := survey.AskOne(prompt, &response, survey.Required)
-	HandleError(err)
+	err := survey.AskOne(prompt, &response, survey.Required, askOptions(stdio)...)
+	return response, err
+}
 
+// Select lets the user select one of the specified options, exiting the process if the prompt fails. Use SelectE
+// to handle errors.
+func Select(message string, options []string, defaultValue ...string) string {
+	response, err := SelectE(message, options, defaultValue...)
+	HandleError(err)
 	return response
 }
 
-func Select(message string, options []string, defaultValue ...string) string {
+// SelectE lets the user select one of the specified options, returning terminal.InterruptErr if the user interrupts the prompt.
+// Options are always displayed in alphabetical order: the specified slice is sorted in place.
+func SelectE(message string, options []string, defaultValue ...string) (string, error) {
+	return selectOne(message, options, defaultValue)
+}
+
+// SelectInOrderE behaves like SelectE but displays the options in the specified order, e.g. for numeric versions
+func SelectInOrderE(message string, options []string, defaultValue ...string) (string, error) {
+	return selectSorted(message, options, defaultValue)
+}
+
+// SelectWithBackE behaves like SelectE but also offers to go back to the previous step, returning ErrGoBack if the user does so
+func SelectWithBackE(message string, options []string, defaultValue ...string) (string, error) {
+	return selectWithBack(message, options, defaultValue)
+}
+
+// selectOne lets the user select one of the specified options using the specified Stdio instance (useful for testing purposes)
+func selectOne(message string, options []string, defaultValue []string, stdio ...terminal.Stdio) (string, error) {
+	sort.Strings(options)
+	return selectSorted(message, options, defaultValue, stdio...)
+}
+
+// selectWithBack lets the user select one of the specified options or go back using the specified Stdio instance (useful for
+// testing purposes)
+func selectWithBack(message string, options []string, defaultValue []string, stdio ...terminal.Stdio) (string, error) {
 	sort.Strings(options)
+	// make sure that we don't modify the caller's slice beyond sorting it
+	answer, err := selectSorted(message, append(options[:len(options):len(options)], GoBack), defaultValue, stdio...)
+	if err == nil && answer == GoBack {
+		return "", ErrGoBack
+	}
+	return answer, err
+}
+
+// selectSorted lets the user select one of the specified options, displayed in the specified order
+func selectSorted(message string, options []string, defaultValue []string, stdio ...terminal.Stdio) (string, error) {
 	prompt := &survey.Select{
 		Message: message,
 		Options: options,
@@ -44,23 +105,187 @@ func Select(message string, options []string, defaultValue ...string) string {
 	if len(defaultValue) == 1 {
 		prompt.Default = defaultValue[0]
 	}
-	return askOne(prompt)
+	return askOne(prompt, survey.Required, stdio...)
 }
 
+// MultiSelect lets the user select several of the specified options, exiting the process if the prompt fails. Use
+// MultiSelectE to handle errors.
 func MultiSelect(message string, options []string, defaultValues []string) []string {
+	response, err := MultiSelectE(message, options, defaultValues)
+	HandleError(err)
+	return response
+}
+
+// MultiSelectE lets the user select several of the specified options, returning terminal.InterruptErr if the user interrupts
+// the prompt. Options are always displayed in alphabetical order: the specified slice is sorted in place.
+func MultiSelectE(message string, options []string, defaultValues []string) ([]string, error) {
+	return multiSelect(message, options, defaultValues)
+}
+
+// multiSelect lets the user select several of the specified options using the specified Stdio instance (useful for testing
+// purposes)
+func multiSelect(message string, options []string, defaultValues []string, stdio ...terminal.Stdio) ([]string, error) {
 	sort.Strings(options)
+	return multiSelectSorted(message, options, defaultValues, nil, stdio...)
+}
+
+// multiSelectSorted lets the user select several of the specified options, displayed in the specified order and narrowed using
+// the specified filter as the user types, survey's default filter being used if nil
+func multiSelectSorted(message string, options []string, defaultValues []string, filter filterFn, stdio ...terminal.Stdio) ([]string, error) {
 	modules := []string{}
 	prompt := &survey.MultiSelect{
-		Message: message,
-		Options: options,
-		Default: defaultValues,
+		Message:  message,
+		Options:  options,
+		Default:  defaultValues,
+		FilterFn: filter,
 	}
-	err := survey.AskOne(prompt, &modules, survey.Required)
-	HandleError(err)
-	return modules
+	err := survey.AskOne(prompt, &modules, survey.Validator(validation.NonEmptySelectionValidator), askOptions(stdio)...)
+	return modules, err
+}
+
+// MultiSelectDescribedE lets the user select several of the specified options, displayed with their description if any, and
+// returns the names of the selected options. Options are indexed by name and displayed in alphabetical order.
+func MultiSelectDescribedE(message string, options map[string]string, defaultValues []string) ([]string, error) {
+	return multiSelectDescribed(message, options, nil, defaultValues, false, false)
+}
+
+// MultiSelectDescribedWithBackE behaves like MultiSelectDescribedE but also offers to go back to the previous step, returning
+// ErrGoBack if the user selects that option
+func MultiSelectDescribedWithBackE(message string, options map[string]string, defaultValues []string) ([]string, error) {
+	return multiSelectDescribed(message, options, nil, defaultValues, true, false)
 }
 
+// MultiSelectSearchableE behaves like MultiSelectDescribedE but groups the options by category, categories being indexed by option
+// name, and lets the user narrow the displayed options by typing text to look for in the option names and descriptions, ignoring
+// case. Groups are displayed in alphabetical order, uncategorized options last.
+func MultiSelectSearchableE(message string, options map[string]string, categories map[string]string, defaultValues []string) ([]string, error) {
+	return multiSelectDescribed(message, options, categories, defaultValues, false, true)
+}
+
+// MultiSelectSearchableWithBackE behaves like MultiSelectSearchableE but also offers to go back to the previous step, returning
+// ErrGoBack if the user selects that option
+func MultiSelectSearchableWithBackE(message string, options map[string]string, categories map[string]string, defaultValues []string) ([]string, error) {
+	return multiSelectDescribed(message, options, categories, defaultValues, true, true)
+}
+
+// filterFn narrows the specified options to the ones matching the text typed by the user
+type filterFn func(filter string, options []string) []string
+
+// multiSelectDescribed lets the user select several of the specified described options, grouped by category if any, or go back
+// if goBack is true, using the specified Stdio instance (useful for testing purposes). If searchable is true, typed text is only
+// matched against the option names and descriptions.
+func multiSelectDescribed(message string, options map[string]string, categories map[string]string, defaultValues []string, goBack, searchable bool, stdio ...terminal.Stdio) ([]string, error) {
+	labels := make([]string, 0, len(options))
+	names := make(map[string]string, len(options))
+	for name, description := range options {
+		label := categorizedOption(categories[name], describedOption(name, description))
+		labels = append(labels, label)
+		names[label] = name
+	}
+
+	defaultLabels := make([]string, 0, len(defaultValues))
+	for _, name := range defaultValues {
+		if description, ok := options[name]; ok {
+			defaultLabels = append(defaultLabels, categorizedOption(categories[name], describedOption(name, description)))
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		ci, cj := categories[names[labels[i]]], categories[names[labels[j]]]
+		if ci != cj {
+			// uncategorized options come last
+			return len(cj) == 0 || (len(ci) > 0 && ci < cj)
+		}
+		return labels[i] < labels[j]
+	})
+	if goBack {
+		labels = append(labels, GoBack)
+	}
+
+	var filter filterFn
+	if searchable {
+		message += " (type to filter)"
+		filter = nameOrDescriptionFilter(names, options)
+	}
+
+	selected, err := multiSelectSorted(message, labels, defaultLabels, filter, stdio...)
+	for i, label := range selected {
+		if label == GoBack {
+			return nil, ErrGoBack
+		}
+		selected[i] = names[label]
+	}
+	return selected, err
+}
+
+// nameOrDescriptionFilter returns a filter matching the labels whose option, looked up using the specified option names indexed
+// by label, has a name or description containing the typed text, ignoring case. Labels which aren't options, such as GoBack, are
+// always kept so that they remain reachable.
+func nameOrDescriptionFilter(names map[string]string, options map[string]string) filterFn {
+	return func(filter string, labels []string) []string {
+		filter = strings.ToLower(filter)
+		result := make([]string, 0, len(labels))
+		for _, label := range labels {
+			name, ok := names[label]
+			if !ok || strings.Contains(strings.ToLower(name), filter) || strings.Contains(strings.ToLower(options[name]), filter) {
+				result = append(result, label)
+			}
+		}
+		return result
+	}
+}
+
+// describedOption computes the label used to display the specified option along with its description
+func describedOption(name, description string) string {
+	if len(description) == 0 {
+		return name
+	}
+	return name + " - " + description
+}
+
+// categorizedOption computes the label used to display the specified option label along with its category
+func categorizedOption(category, label string) string {
+	if len(category) == 0 {
+		return label
+	}
+	return "[" + category + "] " + label
+}
+
+// Ask asks the user for a value unless one was already provided, exiting the process if the prompt fails. Use AskE
+// to handle errors.
 func Ask(message, provided string, defaultValue ...string) string {
+	response, err := AskE(message, provided, defaultValue...)
+	HandleError(err)
+	return response
+}
+
+// AskE asks the user for a value unless one was already provided, returning terminal.InterruptErr if the user interrupts the
+// prompt
+func AskE(message, provided string, defaultValue ...string) (string, error) {
+	return AskValidatedE(message, provided, validation.NilValidator, defaultValue...)
+}
+
+// AskValidated asks the user for a value using the specified message unless a valid value was already provided, in which case
+// it is simply displayed. The specified validator is used to validate both the provided value and the user's input.
+func AskValidated(message, provided string, validator validation.Validator, defaultValue ...string) string {
+	response, err := AskValidatedE(message, provided, validator, defaultValue...)
+	HandleError(err)
+	return response
+}
+
+// AskValidatedE behaves like AskValidated but returns terminal.InterruptErr if the user interrupts the prompt
+func AskValidatedE(message, provided string, validator validation.Validator, defaultValue ...string) (string, error) {
+	return askValidated(os.Stdout, message, provided, validator, defaultValue)
+}
+
+// AskValidatedToE behaves like AskValidatedE but displays the provided value, if valid, on the specified output
+func AskValidatedToE(out io.Writer, message, provided string, validator validation.Validator, defaultValue ...string) (string, error) {
+	return askValidated(out, message, provided, validator, defaultValue)
+}
+
+// askValidated asks the user for a value using the specified Stdio instance (useful for testing purposes), displaying the
+// provided value on the specified output if it is valid
+func askValidated(out io.Writer, message, provided string, validator validation.Validator, defaultValue []string, stdio ...terminal.Stdio) (string, error) {
 	input := &survey.Input{
 		Message: message,
 	}
@@ -70,20 +295,28 @@ func Ask(message, provided string, defaultValue ...string) string {
 	}
 
 	if len(provided) > 0 {
-		// todo: validate provided and ask if value is invalid
-		OutputSelection("Selected "+message, provided)
-		return provided
+		err := validator(provided)
+		if err == nil {
+			OutputSelection(out, "Selected "+message, provided)
+			return provided, nil
+		}
+		input.Message = fmt.Sprintf("%s\n%s", colorize(ansi.Red, err.Error(), colorsEnabled(os.Stdout)), message)
 	}
-	return askOne(input)
+	return askOne(input, survey.ComposeValidators(survey.Required, survey.Validator(validator)), stdio...)
 }
 
-func askOne(prompt survey.Prompt, stdio ...terminal.Stdio) string {
+func askOne(prompt survey.Prompt, validator survey.Validator, stdio ...terminal.Stdio) (string, error) {
 	var response string
+	err := survey.AskOne(prompt, &response, validator, askOptions(stdio)...)
+	return response, err
+}
 
-	err := survey.AskOne(prompt, &response, survey.Required)
-	HandleError(err)
-
-	return response
+// askOptions converts the optional Stdio instance into the survey option needed to use it
+func askOptions(stdio []terminal.Stdio) []survey.AskOpt {
+	if len(stdio) == 1 {
+		return []survey.AskOpt{survey.WithStdio(stdio[0].In, stdio[0].Out, stdio[0].Err)}
+	}
+	return nil
 }
 
 // GetValidatorFor returns an implementation specific validator for the given validatable to avoid type casting at each calling
@@ -92,10 +325,15 @@ func GetValidatorFor(prop validation.Validatable) survey.Validator {
 	return survey.Validator(validation.GetValidatorFor(prop))
 }
 
-func OutputSelection(msg, choice string) {
-	fmt.Println(ansi.Green + ansi.ColorCode("default+hb") + msg + ": " + ansi.Cyan + choice + ansi.Reset)
+// OutputSelection displays the specified selection on the specified output
+func OutputSelection(out io.Writer, msg, choice string) {
+	if !colorsEnabled(out) {
+		fmt.Fprintln(out, msg+": "+choice)
+		return
+	}
+	fmt.Fprintln(out, ansi.Green+ansi.ColorCode("default+hb")+msg+": "+ansi.Cyan+choice+ansi.Reset)
 }
 
 func ErrorMessage(msg, wrong string) string {
-	return fmt.Sprintf("%s%s: %s%s\nSelect other(s) from:", ansi.Red, msg, wrong, ansi.ColorCode("default"))
+	return fmt.Sprintf("%s\nSelect other(s) from:", colorize(ansi.Red, msg+": "+wrong, colorsEnabled(os.Stdout)))
 }
@@ -0,0 +1,361 @@
+//go:build !windows
+
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"github.com/Netflix/go-expect"
+	"github.com/hinshun/vt10x"
+	"github.com/mgutz/ansi"
+	"github.com/snowdrop/odo-scaffold-plugin/pkg/validation"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// runPromptTest runs the specified prompt against a simulated terminal driven by the specified procedure
+func runPromptTest(t *testing.T, procedure func(*expect.Console), prompt func(terminal.Stdio)) {
+	buf := new(bytes.Buffer)
+	c, _, err := vt10x.NewVT10XConsole(expect.WithStdout(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		procedure(c)
+	}()
+
+	prompt(terminal.Stdio{In: c.Tty(), Out: c.Tty(), Err: c.Tty()})
+
+	c.Tty().Close()
+	<-done
+}
+
+func TestProceed(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "default", input: "", expected: true},
+		{name: "no", input: "n", expected: false},
+		{name: "yes", input: "y", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result bool
+			var err error
+			runPromptTest(t, func(c *expect.Console) {
+				c.ExpectString("Continue")
+				c.SendLine(tt.input)
+				c.ExpectEOF()
+			}, func(stdio terminal.Stdio) {
+				result, err = proceed("Continue", stdio)
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultValue []string
+		input        string
+		expected     string
+	}{
+		{name: "first sorted option", input: "", expected: "a"},
+		{name: "default", defaultValue: []string{"c"}, input: "", expected: "c"},
+		{name: "move down from default", defaultValue: []string{"b"}, input: string(terminal.KeyArrowDown), expected: "c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result string
+			var err error
+			runPromptTest(t, func(c *expect.Console) {
+				c.ExpectString("Choose")
+				c.SendLine(tt.input)
+				c.ExpectEOF()
+			}, func(stdio terminal.Stdio) {
+				result, err = selectOne("Choose", []string{"c", "a", "b"}, tt.defaultValue, stdio)
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMultiSelect(t *testing.T) {
+	var result []string
+	var err error
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Modules")
+		// select the first sorted option in addition to the default one
+		c.Send(" ")
+		c.SendLine("")
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		result, err = multiSelect("Modules", []string{"web", "core", "jpa"}, []string{"web"}, stdio)
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"core", "web"}, result) {
+		t.Errorf("expected [core web], got %v", result)
+	}
+}
+
+func TestAsk(t *testing.T) {
+	tests := []struct {
+		name         string
+		provided     string
+		defaultValue []string
+		input        string
+		expected     string
+	}{
+		{name: "default", defaultValue: []string{"me.snowdrop"}, input: "", expected: "me.snowdrop"},
+		{name: "input", defaultValue: []string{"me.snowdrop"}, input: "org.acme", expected: "org.acme"},
+		{name: "invalid provided", provided: "Not Valid", input: "org.acme", expected: "org.acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result string
+			var err error
+			runPromptTest(t, func(c *expect.Console) {
+				c.ExpectString("Group Id")
+				c.SendLine(tt.input)
+				c.ExpectEOF()
+			}, func(stdio terminal.Stdio) {
+				result, err = askValidated(new(bytes.Buffer), "Group Id", tt.provided, validation.GroupIdValidator, tt.defaultValue, stdio)
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAskProvided(t *testing.T) {
+	// a valid provided value shouldn't trigger any prompt
+	var out bytes.Buffer
+	result, err := askValidated(&out, "Group Id", "me.snowdrop", validation.GroupIdValidator, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "me.snowdrop" {
+		t.Errorf("expected provided value to be returned, got %s", result)
+	}
+	if out.String() != "Selected Group Id: me.snowdrop\n" {
+		t.Errorf("expected provided value to be displayed on the specified output, got %q", out.String())
+	}
+}
+
+func TestSelectInterrupted(t *testing.T) {
+	var err error
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Choose")
+		c.Send(string(terminal.KeyInterrupt))
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		_, err = selectOne("Choose", []string{"a", "b"}, nil, stdio)
+	})
+
+	if err != terminal.InterruptErr {
+		t.Errorf("expected interrupt error, got %v", err)
+	}
+}
+
+func TestMultiSelectDescribed(t *testing.T) {
+	var result []string
+	var err error
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Modules")
+		// select the first sorted option in addition to the default one
+		c.Send(" ")
+		c.SendLine("")
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		options := map[string]string{"web": "Spring MVC", "core": "Core starter", "jpa": ""}
+		result, err = multiSelectDescribed("Modules", options, nil, []string{"web", "unknown"}, false, false, stdio)
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"core", "web"}, result) {
+		t.Errorf("expected selected module names [core web], got %v", result)
+	}
+}
+
+func TestMultiSelectSearchable(t *testing.T) {
+	var result []string
+	var err error
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Modules (type to filter)")
+		// options are grouped by category, uncategorized ones last
+		c.ExpectString("[data] jpa - JPA")
+		c.ExpectString("[web] web - Spring MVC")
+		c.ExpectString("core - Core starter")
+		// typing narrows the options on description, the category not being matched
+		c.Send("mvc")
+		c.ExpectString("web - Spring MVC")
+		c.Send(" ")
+		c.SendLine("")
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		options := map[string]string{"web": "Spring MVC", "core": "Core starter", "jpa": "JPA"}
+		categories := map[string]string{"web": "web", "jpa": "data"}
+		result, err = multiSelectDescribed("Modules", options, categories, nil, false, true, stdio)
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"web"}, result) {
+		t.Errorf("expected selected module names [web], got %v", result)
+	}
+}
+
+func TestNameOrDescriptionFilter(t *testing.T) {
+	names := map[string]string{"[web] web - Spring MVC": "web", "[data] jpa - JPA": "jpa", "core - Core starter": "core"}
+	options := map[string]string{"web": "Spring MVC", "jpa": "JPA", "core": "Core starter"}
+	labels := []string{"[data] jpa - JPA", "[web] web - Spring MVC", "core - Core starter", GoBack}
+	filter := nameOrDescriptionFilter(names, options)
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "JP", want: []string{"[data] jpa - JPA", GoBack}},
+		{filter: "starter", want: []string{"core - Core starter", GoBack}},
+		{filter: "data", want: []string{GoBack}},
+		{filter: "", want: labels},
+	}
+	for _, tt := range tests {
+		if got := filter(tt.filter, labels); !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("filter %q: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+}
+
+func TestMultiSelectRequiresSelection(t *testing.T) {
+	var result []string
+	var err error
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Modules")
+		// submitting an empty selection should re-prompt the user
+		c.SendLine("")
+		c.ExpectString("at least one option must be selected")
+		c.Send(" ")
+		c.SendLine("")
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		result, err = multiSelect("Modules", []string{"web", "core"}, nil, stdio)
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"core"}, result) {
+		t.Errorf("expected [core], got %v", result)
+	}
+}
+
+func TestGoBack(t *testing.T) {
+	var selected string
+	var err error
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Choose")
+		// the go back option is displayed last
+		c.Send(string(terminal.KeyArrowUp))
+		c.SendLine("")
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		selected, err = selectWithBack("Choose", []string{"b", "a"}, nil, stdio)
+	})
+	if err != ErrGoBack {
+		t.Errorf("expected to go back, got %s (%v)", selected, err)
+	}
+
+	var modules []string
+	runPromptTest(t, func(c *expect.Console) {
+		c.ExpectString("Modules")
+		c.Send(string(terminal.KeyArrowUp))
+		c.Send(" ")
+		c.SendLine("")
+		c.ExpectEOF()
+	}, func(stdio terminal.Stdio) {
+		modules, err = multiSelectDescribed("Modules", map[string]string{"web": "Spring MVC"}, nil, []string{"web"}, true, false, stdio)
+	})
+	if err != ErrGoBack {
+		t.Errorf("expected to go back, got %v (%v)", modules, err)
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	var status int
+	exited := false
+	exit = func(code int) {
+		exited, status = true, code
+	}
+	defer func() { exit = os.Exit }()
+
+	HandleError(nil)
+	if exited {
+		t.Error("no error should not exit")
+	}
+
+	for _, err := range []error{terminal.InterruptErr, errors.New("broken terminal")} {
+		exited, status = false, 0
+		HandleError(err)
+		if !exited || status != 1 {
+			t.Errorf("expected %v to exit with status 1, got exited=%v status=%d", err, exited, status)
+		}
+	}
+}
+
+func TestStyledOutput(t *testing.T) {
+	if _, disabled := os.LookupEnv(noColorEnvVar); disabled {
+		t.Skipf("colors are disabled by %s", noColorEnvVar)
+	}
+	c, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// colors only depend on the output the message is written to, stdout possibly being redirected while stderr is a terminal
+	if styled := Success(c.Tty(), "done"); styled != ansi.Green+"done"+ansi.Reset {
+		t.Errorf("expected message written to a terminal to be styled, got %q", styled)
+	}
+	if styled := Warning(c.Tty(), "careful"); styled != ansi.Yellow+"careful"+ansi.Reset {
+		t.Errorf("expected message written to a terminal to be styled, got %q", styled)
+	}
+	if plain := Failure(new(bytes.Buffer), "failed"); plain != "failed" {
+		t.Errorf("expected message not written to a terminal to be plain, got %q", plain)
+	}
+}
@@ -2,6 +2,9 @@ package validation
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -20,3 +23,122 @@ func ValidateName(name string) error {
 	return nil
 
 }
+
+var (
+	groupIdPattern        = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+	artifactIdPattern     = regexp.MustCompile(`^[a-z0-9-]+$`)
+	versionPattern        = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*([.-][A-Za-z0-9]+)*$`)
+	javaIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	invalidJavaCharacters = regexp.MustCompile(`[^A-Za-z0-9_$]`)
+)
+
+// javaKeywords are the reserved words that cannot be used as Java identifiers
+var javaKeywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true, "catch": true, "char": true,
+	"class": true, "const": true, "continue": true, "default": true, "do": true, "double": true, "else": true, "enum": true,
+	"extends": true, "false": true, "final": true, "finally": true, "float": true, "for": true, "goto": true, "if": true,
+	"implements": true, "import": true, "instanceof": true, "int": true, "interface": true, "long": true, "native": true,
+	"new": true, "null": true, "package": true, "private": true, "protected": true, "public": true, "return": true,
+	"short": true, "static": true, "strictfp": true, "super": true, "switch": true, "synchronized": true, "this": true,
+	"throw": true, "throws": true, "transient": true, "true": true, "try": true, "void": true, "volatile": true, "while": true,
+}
+
+// ValidateGroupId checks that the specified Maven groupId is made of dot-separated lowercase identifiers (e.g. me.snowdrop)
+func ValidateGroupId(groupId string) error {
+	if !groupIdPattern.MatchString(groupId) {
+		return fmt.Errorf("%s is not a valid group id: it must be made of dot-separated lowercase identifiers, e.g. me.snowdrop", groupId)
+	}
+	return nil
+}
+
+// ValidateArtifactId checks that the specified Maven artifactId only contains lowercase letters, digits and dashes
+func ValidateArtifactId(artifactId string) error {
+	if !artifactIdPattern.MatchString(artifactId) {
+		return fmt.Errorf("%s is not a valid artifact id: it must only contain lowercase letters, digits and dashes", artifactId)
+	}
+	return nil
+}
+
+// ValidateVersion checks that the specified Maven version is made of dot-separated numbers optionally followed by a qualifier
+// introduced by a dash or a dot, e.g. 1.0.0-SNAPSHOT or 2.1.3.RELEASE
+func ValidateVersion(version string) error {
+	if !versionPattern.MatchString(version) {
+		return fmt.Errorf("%s is not a valid version: it must be made of dot-separated numbers optionally followed by a qualifier, e.g. 1.0.0-SNAPSHOT", version)
+	}
+	return nil
+}
+
+// ValidateOutDir checks that the specified project location is either an absolute path other than the root directory or the name
+// of a single directory, i.e. that it is not empty and doesn't contain any path separator nor refer to the current or parent
+// directory. Templated locations, containing variables such as {artifactId}, may also be nested relative paths since they are
+// typically used to organize projects, e.g. projects/{artifactId}.
+func ValidateOutDir(outDir string) error {
+	if strings.Contains(outDir, "{") {
+		return ValidateExpandedOutDir(outDir)
+	}
+	if filepath.IsAbs(outDir) {
+		return validateAbsoluteOutDir(outDir)
+	}
+	if len(outDir) == 0 || outDir == "." || outDir == ".." || strings.ContainsAny(outDir, "/"+string(os.PathSeparator)) {
+		return fmt.Errorf("%s is not a valid project location: it must be the name of an immediate child directory or an absolute path, "+
+			"nested directories being only allowed in templated locations such as projects/{artifactId}", outDir)
+	}
+	return nil
+}
+
+// ValidateExpandedOutDir checks that the specified project location, resulting from the expansion of a templated location, is
+// either an absolute path other than the root directory or a path relative to the current directory which doesn't escape it,
+// i.e. that none of its segments is empty or refers to the current or parent directory
+func ValidateExpandedOutDir(outDir string) error {
+	if filepath.IsAbs(outDir) {
+		return validateAbsoluteOutDir(outDir)
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(outDir), "/") {
+		if len(segment) == 0 || segment == "." || segment == ".." {
+			return fmt.Errorf("%s is not a valid project location: it must be a child directory of the current directory or an absolute path", outDir)
+		}
+	}
+	return nil
+}
+
+// validateAbsoluteOutDir checks that the specified absolute project location is not the root directory
+func validateAbsoluteOutDir(outDir string) error {
+	if clean := filepath.Clean(outDir); filepath.Dir(clean) == clean {
+		return fmt.Errorf("%s is not a valid project location: the project cannot be created at the root of the file system", outDir)
+	}
+	return nil
+}
+
+// ValidatePackageName checks that the specified Java package name is made of dot-separated Java identifiers which are not
+// reserved keywords
+func ValidatePackageName(packageName string) error {
+	for _, segment := range strings.Split(packageName, ".") {
+		switch {
+		case len(segment) == 0:
+			return fmt.Errorf("%s is not a valid package name: it contains an empty segment", packageName)
+		case !javaIdentifierPattern.MatchString(segment):
+			return fmt.Errorf("%s is not a valid package name: '%s' is not a valid Java identifier", packageName, segment)
+		case javaKeywords[segment]:
+			return fmt.Errorf("%s is not a valid package name: '%s' is a reserved Java keyword", packageName, segment)
+		}
+	}
+	return nil
+}
+
+// SanitizePackageName turns the specified package name into a valid one, removing characters which are not allowed in Java
+// identifiers (e.g. dashes coming from an artifactId) as well as empty segments and prefixing segments which are reserved
+// keywords or start with a digit with an underscore
+func SanitizePackageName(packageName string) string {
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(packageName, ".") {
+		segment = invalidJavaCharacters.ReplaceAllString(segment, "")
+		if len(segment) == 0 {
+			continue
+		}
+		if javaKeywords[segment] || (segment[0] >= '0' && segment[0] <= '9') {
+			segment = "_" + segment
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, ".")
+}
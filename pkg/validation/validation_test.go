@@ -143,3 +143,177 @@ func TestGetValidator(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateGroupId(t *testing.T) {
+	tests := []struct {
+		groupId string
+		wantErr bool
+	}{
+		{groupId: "me", wantErr: false},
+		{groupId: "me.snowdrop", wantErr: false},
+		{groupId: "me.snowdrop_2.demo", wantErr: false},
+		{groupId: "", wantErr: true},
+		{groupId: "Me.Snowdrop", wantErr: true},
+		{groupId: "me.snowdrop.", wantErr: true},
+		{groupId: "me..snowdrop", wantErr: true},
+		{groupId: "me.2snowdrop", wantErr: true},
+		{groupId: "me snowdrop", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.groupId, func(t *testing.T) {
+			if err := ValidateGroupId(tt.groupId); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, But got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateArtifactId(t *testing.T) {
+	tests := []struct {
+		artifactId string
+		wantErr    bool
+	}{
+		{artifactId: "demo", wantErr: false},
+		{artifactId: "my-demo-2", wantErr: false},
+		{artifactId: "", wantErr: true},
+		{artifactId: "Demo", wantErr: true},
+		{artifactId: "my demo", wantErr: true},
+		{artifactId: "my.demo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.artifactId, func(t *testing.T) {
+			if err := ValidateArtifactId(tt.artifactId); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, But got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{version: "1.0.0-SNAPSHOT", wantErr: false},
+		{version: "2.1.3.RELEASE", wantErr: false},
+		{version: "1", wantErr: false},
+		{version: "1.0.0-beta-1", wantErr: false},
+		{version: "", wantErr: true},
+		{version: "1,0,0", wantErr: true},
+		{version: "v1.0", wantErr: true},
+		{version: "1..0", wantErr: true},
+		{version: "1.0-", wantErr: true},
+		{version: "1.0 SNAPSHOT", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if err := ValidateVersion(tt.version); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, But got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateOutDir(t *testing.T) {
+	tests := []struct {
+		outDir  string
+		wantErr bool
+	}{
+		{outDir: "myproject", wantErr: false},
+		{outDir: "my.project", wantErr: false},
+		{outDir: "", wantErr: true},
+		{outDir: ".", wantErr: true},
+		{outDir: "..", wantErr: true},
+		{outDir: "../myproject", wantErr: true},
+		// nested directories are only accepted in templated locations, which still cannot escape the current directory
+		{outDir: "nested/myproject", wantErr: true},
+		{outDir: "nested/{artifactId}", wantErr: false},
+		{outDir: "{groupId}/{artifactId}", wantErr: false},
+		{outDir: "../{artifactId}", wantErr: true},
+		{outDir: "nested/../{artifactId}", wantErr: true},
+		{outDir: "nested//{artifactId}", wantErr: true},
+		{outDir: "{artifactId}/", wantErr: true},
+		{outDir: "/abs/{artifactId}", wantErr: false},
+		{outDir: "/abs/path", wantErr: false},
+		{outDir: "/abs/../path", wantErr: false},
+		{outDir: "/", wantErr: true},
+		{outDir: "/abs/..", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outDir, func(t *testing.T) {
+			if err := ValidateOutDir(tt.outDir); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, But got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateExpandedOutDir(t *testing.T) {
+	tests := []struct {
+		outDir  string
+		wantErr bool
+	}{
+		{outDir: "myproject", wantErr: false},
+		{outDir: "nested/myproject", wantErr: false},
+		{outDir: "../x", wantErr: true},
+		{outDir: "nested/../myproject", wantErr: true},
+		{outDir: "nested//myproject", wantErr: true},
+		{outDir: "nested/", wantErr: true},
+		{outDir: "", wantErr: true},
+		{outDir: "/abs/path", wantErr: false},
+		{outDir: "/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outDir, func(t *testing.T) {
+			if err := ValidateExpandedOutDir(tt.outDir); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, But got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePackageName(t *testing.T) {
+	tests := []struct {
+		packageName string
+		wantErr     bool
+	}{
+		{packageName: "me.snowdrop.demo", wantErr: false},
+		{packageName: "me.snowdrop.my_project", wantErr: false},
+		{packageName: "me.snowdrop.my-project", wantErr: true},
+		{packageName: "me.snowdrop.2demo", wantErr: true},
+		{packageName: "me.snowdrop.new", wantErr: true},
+		{packageName: "me..demo", wantErr: true},
+		{packageName: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.packageName, func(t *testing.T) {
+			if err := ValidatePackageName(tt.packageName); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, But got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSanitizePackageName(t *testing.T) {
+	tests := []struct {
+		packageName string
+		expected    string
+	}{
+		{packageName: "me.snowdrop.demo", expected: "me.snowdrop.demo"},
+		{packageName: "me.snowdrop.my-project", expected: "me.snowdrop.myproject"},
+		{packageName: "me.snowdrop.2demo", expected: "me.snowdrop._2demo"},
+		{packageName: "me.snowdrop.new", expected: "me.snowdrop._new"},
+		{packageName: "me.snowdrop.-", expected: "me.snowdrop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.packageName, func(t *testing.T) {
+			sanitized := SanitizePackageName(tt.packageName)
+			if sanitized != tt.expected {
+				t.Errorf("Expected %s, But got %s", tt.expected, sanitized)
+			}
+			if err := ValidatePackageName(sanitized); err != nil {
+				t.Errorf("Sanitized package name should be valid, got %v", err)
+			}
+		})
+	}
+}
@@ -15,6 +15,63 @@ func NameValidator(name interface{}) error {
 	return fmt.Errorf("can only validate strings, got %v", name)
 }
 
+// GroupIdValidator provides a Validator view of the ValidateGroupId function.
+func GroupIdValidator(groupId interface{}) error {
+	if s, ok := groupId.(string); ok {
+		return ValidateGroupId(s)
+	}
+
+	return fmt.Errorf("can only validate strings, got %v", groupId)
+}
+
+// ArtifactIdValidator provides a Validator view of the ValidateArtifactId function.
+func ArtifactIdValidator(artifactId interface{}) error {
+	if s, ok := artifactId.(string); ok {
+		return ValidateArtifactId(s)
+	}
+
+	return fmt.Errorf("can only validate strings, got %v", artifactId)
+}
+
+// VersionValidator provides a Validator view of the ValidateVersion function.
+func VersionValidator(version interface{}) error {
+	if s, ok := version.(string); ok {
+		return ValidateVersion(s)
+	}
+
+	return fmt.Errorf("can only validate strings, got %v", version)
+}
+
+// PackageNameValidator provides a Validator view of the ValidatePackageName function.
+func PackageNameValidator(packageName interface{}) error {
+	if s, ok := packageName.(string); ok {
+		return ValidatePackageName(s)
+	}
+
+	return fmt.Errorf("can only validate strings, got %v", packageName)
+}
+
+// OutDirValidator provides a Validator view of the ValidateOutDir function.
+func OutDirValidator(outDir interface{}) error {
+	if s, ok := outDir.(string); ok {
+		return ValidateOutDir(s)
+	}
+
+	return fmt.Errorf("can only validate strings, got %v", outDir)
+}
+
+// NonEmptySelectionValidator validates that at least one option was selected in a multiple selection prompt
+func NonEmptySelectionValidator(ans interface{}) error {
+	if selected, ok := ans.([]string); ok {
+		if len(selected) == 0 {
+			return fmt.Errorf("at least one option must be selected, use space to select an option")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("can only validate selected options, got %v", ans)
+}
+
 // Validator is a function that validates that the provided interface conforms to expectations or return an error
 type Validator func(interface{}) error
 
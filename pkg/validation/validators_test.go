@@ -54,3 +54,80 @@ func TestNameValidator(t *testing.T) {
 		}
 	}
 }
+
+func TestGroupIdValidator(t *testing.T) {
+	err := GroupIdValidator("me.snowdrop")
+	if err != nil {
+		t.Errorf("group id validator should have accepted group id, but got: %v instead", err)
+	}
+
+	err = GroupIdValidator(new(interface{}))
+	if err == nil || !strings.Contains(err.Error(), "can only validate strings") {
+		t.Error("group id validator should report error that it can only validate strings")
+	}
+}
+
+func TestArtifactIdValidator(t *testing.T) {
+	err := ArtifactIdValidator("demo")
+	if err != nil {
+		t.Errorf("artifact id validator should have accepted artifact id, but got: %v instead", err)
+	}
+
+	err = ArtifactIdValidator(new(interface{}))
+	if err == nil || !strings.Contains(err.Error(), "can only validate strings") {
+		t.Error("artifact id validator should report error that it can only validate strings")
+	}
+}
+
+func TestVersionValidator(t *testing.T) {
+	err := VersionValidator("1.0.0-SNAPSHOT")
+	if err != nil {
+		t.Errorf("version validator should have accepted version, but got: %v instead", err)
+	}
+
+	err = VersionValidator(new(interface{}))
+	if err == nil || !strings.Contains(err.Error(), "can only validate strings") {
+		t.Error("version validator should report error that it can only validate strings")
+	}
+}
+
+func TestOutDirValidator(t *testing.T) {
+	err := OutDirValidator("myproject")
+	if err != nil {
+		t.Errorf("out dir validator should have accepted project location, but got: %v instead", err)
+	}
+
+	err = OutDirValidator(new(interface{}))
+	if err == nil || !strings.Contains(err.Error(), "can only validate strings") {
+		t.Error("out dir validator should report error that it can only validate strings")
+	}
+}
+
+func TestNonEmptySelectionValidator(t *testing.T) {
+	err := NonEmptySelectionValidator([]string{"core"})
+	if err != nil {
+		t.Errorf("selection validator should have accepted selection, but got: %v instead", err)
+	}
+
+	err = NonEmptySelectionValidator([]string{})
+	if err == nil || !strings.Contains(err.Error(), "at least one option") {
+		t.Errorf("selection validator should have rejected empty selection, but got: %v instead", err)
+	}
+
+	err = NonEmptySelectionValidator("core")
+	if err == nil || !strings.Contains(err.Error(), "can only validate selected options") {
+		t.Error("selection validator should report error that it can only validate selected options")
+	}
+}
+
+func TestPackageNameValidator(t *testing.T) {
+	err := PackageNameValidator("me.snowdrop.demo")
+	if err != nil {
+		t.Errorf("package name validator should have accepted package name, but got: %v instead", err)
+	}
+
+	err = PackageNameValidator(new(interface{}))
+	if err == nil || !strings.Contains(err.Error(), "can only validate strings") {
+		t.Error("package name validator should report error that it can only validate strings")
+	}
+}